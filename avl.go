@@ -0,0 +1,212 @@
+package rbt
+
+import (
+	"constraints"
+
+	"gotest.com/rbt/compare"
+)
+
+// AVL represents AVL tree. Heights of children of any node differ at most by one.
+type AVL[T constraints.Ordered] struct {
+	Root  *AVLNode[T]
+	count int
+}
+
+type AVLNode[T constraints.Ordered] struct {
+	Left   *AVLNode[T]
+	Right  *AVLNode[T]
+	Height int
+	Value  T
+}
+
+// Insert inserts v to t.
+func (t *AVL[T]) Insert(v T) {
+	t.Root = t.Root.insert(v)
+	t.count++
+}
+
+// Delete deletes v from t and returns true if v was found.
+func (t *AVL[T]) Delete(v T) bool {
+	var ok bool
+	t.Root, ok = t.Root.delete(v)
+	if ok {
+		t.count--
+	}
+
+	return ok
+}
+
+// Contains returns true if t contains value v.
+func (t *AVL[T]) Contains(v T) bool {
+	n := t.Root
+	for n != nil {
+		c := compare.Ordered(v, n.Value)
+		if c == 0 {
+			return true
+		} else if c > 0 {
+			n = n.Right
+		} else {
+			n = n.Left
+		}
+	}
+
+	return false
+}
+
+// Min returns min value in t or false if t is empty.
+func (t *AVL[T]) Min() (T, bool) {
+	var zero T
+	if t.Root == nil {
+		return zero, false
+	}
+
+	return t.Root.min().Value, true
+}
+
+// Max returns max value in t or false if t is empty.
+func (t *AVL[T]) Max() (T, bool) {
+	var zero T
+	if t.Root == nil {
+		return zero, false
+	}
+
+	n := t.Root
+	for n.Right != nil {
+		n = n.Right
+	}
+
+	return n.Value, true
+}
+
+// Len returns number of values in t.
+func (t *AVL[T]) Len() int {
+	return t.count
+}
+
+// Ascend calls fn for values of t in ascending order until fn returns false.
+func (t *AVL[T]) Ascend(fn func(v T) bool) {
+	t.Root.ascend(fn)
+}
+
+func (n *AVLNode[T]) height() int {
+	if n == nil {
+		return 0
+	}
+
+	return n.Height
+}
+
+// update recalculates height of n.
+func (n *AVLNode[T]) update() {
+	lh, rh := n.Left.height(), n.Right.height()
+	if lh > rh {
+		n.Height = lh + 1
+	} else {
+		n.Height = rh + 1
+	}
+}
+
+func (n *AVLNode[T]) rotateLeft() *AVLNode[T] {
+	c := n.Right
+	n.Right = c.Left
+	c.Left = n
+	n.update()
+	c.update()
+
+	return c
+}
+
+func (n *AVLNode[T]) rotateRight() *AVLNode[T] {
+	b := n.Left
+	n.Left = b.Right
+	b.Right = n
+	n.update()
+	b.update()
+
+	return b
+}
+
+// balance restores AVL property for n and returns new root of subtree.
+func (n *AVLNode[T]) balance() *AVLNode[T] {
+	n.update()
+
+	d := n.Left.height() - n.Right.height()
+	if d > 1 {
+		if n.Left.Right.height() > n.Left.Left.height() {
+			n.Left = n.Left.rotateLeft()
+		}
+
+		return n.rotateRight()
+	}
+
+	if d < -1 {
+		if n.Right.Left.height() > n.Right.Right.height() {
+			n.Right = n.Right.rotateRight()
+		}
+
+		return n.rotateLeft()
+	}
+
+	return n
+}
+
+func (n *AVLNode[T]) insert(v T) *AVLNode[T] {
+	if n == nil {
+		return &AVLNode[T]{
+			Value:  v,
+			Height: 1,
+		}
+	}
+
+	if compare.Ordered(v, n.Value) > 0 {
+		n.Right = n.Right.insert(v)
+	} else {
+		n.Left = n.Left.insert(v)
+	}
+
+	return n.balance()
+}
+
+func (n *AVLNode[T]) delete(v T) (*AVLNode[T], bool) {
+	if n == nil {
+		return nil, false
+	}
+
+	var ok bool
+	c := compare.Ordered(v, n.Value)
+	if c == 0 {
+		if n.Left == nil {
+			return n.Right, true
+		}
+
+		if n.Right == nil {
+			return n.Left, true
+		}
+
+		m := n.Right.min()
+		n.Value = m.Value
+		n.Right, ok = n.Right.delete(m.Value)
+	} else if c > 0 {
+		n.Right, ok = n.Right.delete(v)
+	} else {
+		n.Left, ok = n.Left.delete(v)
+	}
+
+	return n.balance(), ok
+}
+
+func (n *AVLNode[T]) min() *AVLNode[T] {
+	for n.Left != nil {
+		n = n.Left
+	}
+
+	return n
+}
+
+func (n *AVLNode[T]) ascend(fn func(v T) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	return n.Left.ascend(fn) && fn(n.Value) && n.Right.ascend(fn)
+}
@@ -0,0 +1,143 @@
+package rbt
+
+import (
+	"gotest.com/rbt/compare"
+)
+
+// InsertBatch inserts values. Batch is sorted first. Batch that is small
+// relative to t is inserted value by value in ascending order, search of
+// every value starts from the previous one, so close values are inserted
+// in amortised O(1) comparisons. Larger batch is merged with values of t
+// and t is rebuilt in O(n + k).
+func (t *Tree[T]) InsertBatch(values []T) {
+	vs := append([]T(nil), values...)
+	sortStable(vs, compare.Ordered[T])
+
+	if !rebuildBatch(t.count, len(vs)) {
+		// every value goes after the previous one, so search starts from it
+		var f *Node[T]
+		for _, v := range vs {
+			f = t.insertNear(f, v)
+		}
+
+		return
+	}
+
+	all := make([]T, 0, t.count)
+	t.Ascend(func(v T) bool {
+		all = append(all, v)
+		return true
+	})
+
+	t.build(merge(make([]T, len(all)+len(vs)), all, vs, compare.Ordered[T]))
+}
+
+// DeleteBatch deletes values and returns number of deleted values, every
+// value of batch deletes one equal value of t. Like InsertBatch, t is
+// rebuilt without deleted values if batch is large relative to t.
+func (t *Tree[T]) DeleteBatch(values []T) int {
+	vs := append([]T(nil), values...)
+	sortStable(vs, compare.Ordered[T])
+
+	if !rebuildBatch(t.count, len(vs)) {
+		d := 0
+		for _, v := range vs {
+			if t.Delete(v) {
+				d++
+			}
+		}
+
+		return d
+	}
+
+	kept := make([]T, 0, t.count)
+	i := 0
+	t.Ascend(func(v T) bool {
+		for i < len(vs) && compare.Ordered(vs[i], v) < 0 {
+			i++
+		}
+
+		if i < len(vs) && compare.Ordered(vs[i], v) == 0 {
+			i++
+		} else {
+			kept = append(kept, v)
+		}
+
+		return true
+	})
+
+	d := t.count - len(kept)
+	t.build(kept)
+
+	return d
+}
+
+// InsertBatch inserts values, see Tree.InsertBatch.
+func (t *TreeCmp[T]) InsertBatch(values []T) {
+	cmp := t.cmp()
+	vs := append([]T(nil), values...)
+	sortStable(vs, cmp)
+
+	if !rebuildBatch(t.count, len(vs)) {
+		// every value goes after the previous one, so search starts from it
+		var f *NodeCmp[T]
+		for _, v := range vs {
+			f = t.insertNear(f, v)
+		}
+
+		return
+	}
+
+	all := make([]T, 0, t.count)
+	t.Ascend(func(v T) bool {
+		all = append(all, v)
+		return true
+	})
+
+	t.build(merge(make([]T, len(all)+len(vs)), all, vs, cmp))
+}
+
+// DeleteBatch deletes values and returns number of deleted values, see Tree.DeleteBatch.
+func (t *TreeCmp[T]) DeleteBatch(values []T) int {
+	cmp := t.cmp()
+	vs := append([]T(nil), values...)
+	sortStable(vs, cmp)
+
+	if !rebuildBatch(t.count, len(vs)) {
+		d := 0
+		for _, v := range vs {
+			if t.Delete(v) {
+				d++
+			}
+		}
+
+		return d
+	}
+
+	kept := make([]T, 0, t.count)
+	i := 0
+	t.Ascend(func(v T) bool {
+		for i < len(vs) && cmp(vs[i], v) < 0 {
+			i++
+		}
+
+		if i < len(vs) && cmp(vs[i], v) == 0 {
+			i++
+		} else {
+			kept = append(kept, v)
+		}
+
+		return true
+	})
+
+	d := t.count - len(kept)
+	t.build(kept)
+
+	return d
+}
+
+// rebuildBatch returns true if batch of k values is large enough to
+// rebuild tree of n values instead of k operations of O(log n).
+func rebuildBatch(n, k int) bool {
+	return 2*k >= n
+}
@@ -0,0 +1,135 @@
+package rbt_test
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"gotest.com/rbt"
+	"gotest.com/rbt/compare"
+)
+
+func TestTreeBatch(t *testing.T) {
+	// small batches are inserted one by one, large ones rebuild the tree
+	for _, k := range []int{1, 10, 100, 5000} {
+		tree := &rbt.Tree[int]{}
+		cmpTree := rbt.NewTreeCmp(compare.Ordered[int])
+		vs := []int{}
+
+		for i := 0; i < 3000; i++ {
+			v := rand.Intn(1000)
+			tree.Insert(v)
+			cmpTree.Insert(v)
+			vs = append(vs, v)
+		}
+
+		batch := make([]int, k)
+		for i := range batch {
+			batch[i] = rand.Intn(1000)
+		}
+
+		tree.InsertBatch(batch)
+		cmpTree.InsertBatch(batch)
+		vs = append(vs, batch...)
+		sort.Ints(vs)
+
+		if err := checkTree(tree.Root); err != nil {
+			t.Fatal(k, err)
+		}
+
+		if err := checkTreeCmp(cmpTree.Root); err != nil {
+			t.Fatal(k, err)
+		}
+
+		if got := treeValues(tree); !reflect.DeepEqual(got, vs) || tree.Len() != len(vs) || cmpTree.Len() != len(vs) {
+			t.Fatal("unexpected values after insert of", k)
+		}
+
+		// deleted values are counted by occurrence, missing ones are skipped
+		del := append(batch, -1, -2)
+		if d := tree.DeleteBatch(del); d != k {
+			t.Fatal("unexpected number of deleted values", d, k)
+		}
+
+		if d := cmpTree.DeleteBatch(del); d != k {
+			t.Fatal("unexpected number of deleted values", d, k)
+		}
+
+		if err := checkTree(tree.Root); err != nil {
+			t.Fatal(k, err)
+		}
+
+		if err := checkTreeCmp(cmpTree.Root); err != nil {
+			t.Fatal(k, err)
+		}
+
+		if tree.Len() != 3000 || cmpTree.Len() != 3000 || tree.HeadSet(1000).Len() != 3000 {
+			t.Fatal("unexpected len after delete of", k)
+		}
+
+		if min, _ := tree.Min(); min != treeValues(tree)[0] {
+			t.Fatal("unexpected min", min)
+		}
+	}
+}
+
+func TestTreeBatchHash(t *testing.T) {
+	h := func(v int) uint64 { return uint64(v) }
+
+	a := &rbt.Tree[int]{}
+	a.EnableSubtreeHash(h)
+	a.InsertBatch(rand.Perm(100))
+
+	b := &rbt.Tree[int]{}
+	b.EnableSubtreeHash(h)
+	for i := 0; i < 100; i++ {
+		b.Insert(i)
+	}
+
+	if a.SubtreeHash() != b.SubtreeHash() {
+		t.Fatal("rebuilt tree has different hash")
+	}
+}
+
+func BenchmarkTreeInsertBatch(b *testing.B) {
+	for _, k := range []int{1 << 12, 1 << 16} {
+		b.Run(fmt.Sprint(k), func(b *testing.B) {
+			benchmarkBatch(b, k, func(tree *rbt.Tree[int], batch []int) {
+				tree.InsertBatch(batch)
+			})
+		})
+	}
+}
+
+func BenchmarkTreeInsertEach(b *testing.B) {
+	for _, k := range []int{1 << 12, 1 << 16} {
+		b.Run(fmt.Sprint(k), func(b *testing.B) {
+			benchmarkBatch(b, k, func(tree *rbt.Tree[int], batch []int) {
+				for _, v := range batch {
+					tree.Insert(v)
+				}
+			})
+		})
+	}
+}
+
+// benchmarkBatch inserts batch of k random values into tree of 1<<16 values.
+func benchmarkBatch(b *testing.B, k int, insert func(tree *rbt.Tree[int], batch []int)) {
+	base := rand.Perm(1 << 16)
+	batch := make([]int, k)
+	for i := range batch {
+		batch[i] = rand.Intn(len(base))
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		tree := rbt.BuildParallel(base, 0)
+		b.StartTimer()
+
+		insert(tree, batch)
+	}
+}
@@ -0,0 +1,396 @@
+package rbt
+
+import (
+	"constraints"
+
+	"gotest.com/rbt/compare"
+)
+
+// DefaultDegree is degree of BTree with zero Degree.
+const DefaultDegree = 32
+
+// BTree represents B-tree. It has the same methods as Tree, but keeps up to
+// 2*Degree-1 values in every node, so search touches less nodes and values
+// are close in memory.
+type BTree[T constraints.Ordered] struct {
+	Root *BNode[T]
+	// Degree is minimum degree of tree, every node except root has from
+	// Degree-1 to 2*Degree-1 values. Degree must be at least 2 and must not be
+	// changed after first insert. Zero Degree means DefaultDegree.
+	Degree int
+	count  int
+}
+
+// BNode is node of BTree. Children is nil for leaf nodes, otherwise
+// it has len(Values)+1 children.
+type BNode[T constraints.Ordered] struct {
+	Values   []T
+	Children []*BNode[T]
+}
+
+// Insert inserts v to t.
+func (t *BTree[T]) Insert(v T) {
+	d := t.degree()
+	t.count++
+
+	if t.Root == nil {
+		t.Root = &BNode[T]{
+			Values: make([]T, 0, 2*d-1),
+		}
+	}
+
+	if len(t.Root.Values) == 2*d-1 {
+		r := &BNode[T]{
+			Children: []*BNode[T]{t.Root},
+		}
+		r.split(0, d)
+		t.Root = r
+	}
+
+	t.Root.insert(v, d)
+}
+
+// Delete deletes v from t and returns true if v was found.
+func (t *BTree[T]) Delete(v T) bool {
+	if t.Root == nil {
+		return false
+	}
+
+	ok := t.Root.delete(v, t.degree())
+
+	// root can lose its last value after merge of children
+	if len(t.Root.Values) == 0 {
+		if t.Root.leaf() {
+			t.Root = nil
+		} else {
+			t.Root = t.Root.Children[0]
+		}
+	}
+
+	if ok {
+		t.count--
+	}
+
+	return ok
+}
+
+// Find returns value of t that is equal to v or false if there is no such value.
+func (t *BTree[T]) Find(v T) (T, bool) {
+	n := t.Root
+	for n != nil {
+		i := n.lowerBound(v)
+		if i < len(n.Values) && compare.Ordered(n.Values[i], v) == 0 {
+			return n.Values[i], true
+		}
+
+		if n.leaf() {
+			break
+		}
+
+		n = n.Children[i]
+	}
+
+	var zero T
+	return zero, false
+}
+
+// Contains returns true if t contains value v.
+func (t *BTree[T]) Contains(v T) bool {
+	_, ok := t.Find(v)
+	return ok
+}
+
+// Min returns min value in t or false if t is empty.
+func (t *BTree[T]) Min() (T, bool) {
+	if t.Root == nil {
+		var zero T
+		return zero, false
+	}
+
+	return t.Root.min(), true
+}
+
+// Max returns max value in t or false if t is empty.
+func (t *BTree[T]) Max() (T, bool) {
+	if t.Root == nil {
+		var zero T
+		return zero, false
+	}
+
+	return t.Root.max(), true
+}
+
+// Len returns number of values in t.
+func (t *BTree[T]) Len() int {
+	return t.count
+}
+
+// Ascend calls fn for values of t in ascending order until fn returns false.
+func (t *BTree[T]) Ascend(fn func(v T) bool) {
+	t.Root.ascend(fn)
+}
+
+// Height returns number of levels in t.
+func (t *BTree[T]) Height() int {
+	h := 0
+	for n := t.Root; n != nil; h++ {
+		if n.leaf() {
+			n = nil
+		} else {
+			n = n.Children[0]
+		}
+	}
+
+	return h
+}
+
+func (t *BTree[T]) degree() int {
+	if t.Degree == 0 {
+		return DefaultDegree
+	}
+
+	if t.Degree < 2 {
+		panic("degree of b-tree must be at least 2")
+	}
+
+	return t.Degree
+}
+
+func (n *BNode[T]) leaf() bool {
+	return n.Children == nil
+}
+
+// lowerBound returns index of first value that is not less than v.
+func (n *BNode[T]) lowerBound(v T) int {
+	i, j := 0, len(n.Values)
+	for i < j {
+		h := int(uint(i+j) >> 1)
+		if compare.Ordered(n.Values[h], v) < 0 {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+
+	return i
+}
+
+// upperBound returns index of first value that is greater than v.
+func (n *BNode[T]) upperBound(v T) int {
+	i, j := 0, len(n.Values)
+	for i < j {
+		h := int(uint(i+j) >> 1)
+		if compare.Ordered(n.Values[h], v) <= 0 {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+
+	return i
+}
+
+// insert inserts v to subtree n, n must be not full.
+// Full children are split on the way down, so split never goes up.
+func (n *BNode[T]) insert(v T, d int) {
+	for {
+		i := n.upperBound(v)
+		if n.leaf() {
+			n.Values = insertAt(n.Values, i, v)
+			return
+		}
+
+		if len(n.Children[i].Values) == 2*d-1 {
+			n.split(i, d)
+			if compare.Ordered(v, n.Values[i]) >= 0 {
+				i++
+			}
+		}
+
+		n = n.Children[i]
+	}
+}
+
+// split splits full child i of n into two nodes with d-1 values and moves
+// median value up to n.
+func (n *BNode[T]) split(i, d int) {
+	c := n.Children[i]
+	r := &BNode[T]{
+		Values: make([]T, d-1, 2*d-1),
+	}
+	copy(r.Values, c.Values[d:])
+
+	if !c.leaf() {
+		r.Children = make([]*BNode[T], d, 2*d)
+		copy(r.Children, c.Children[d:])
+		clearValues(c.Children[d:])
+		c.Children = c.Children[:d]
+	}
+
+	m := c.Values[d-1]
+	clearValues(c.Values[d-1:])
+	c.Values = c.Values[:d-1]
+
+	n.Values = insertAt(n.Values, i, m)
+	n.Children = insertAt(n.Children, i+1, r)
+}
+
+// delete deletes v from subtree n. n must have at least d values unless it is root,
+// so a value can be removed from it without merging n with siblings.
+func (n *BNode[T]) delete(v T, d int) bool {
+	i := n.lowerBound(v)
+
+	if i < len(n.Values) && compare.Ordered(n.Values[i], v) == 0 {
+		if n.leaf() {
+			n.Values = removeAt(n.Values, i)
+			return true
+		}
+
+		// replace v with predecessor or successor if child can give it,
+		// otherwise merge children around v and delete it from merged node
+		if len(n.Children[i].Values) >= d {
+			p := n.Children[i].max()
+			n.Values[i] = p
+			return n.Children[i].delete(p, d)
+		}
+
+		if len(n.Children[i+1].Values) >= d {
+			s := n.Children[i+1].min()
+			n.Values[i] = s
+			return n.Children[i+1].delete(s, d)
+		}
+
+		n.merge(i)
+		return n.Children[i].delete(v, d)
+	}
+
+	if n.leaf() {
+		return false
+	}
+
+	if len(n.Children[i].Values) < d {
+		i = n.fill(i, d)
+	}
+
+	return n.Children[i].delete(v, d)
+}
+
+// fill makes child i of n to have at least d values by borrowing value from
+// sibling or by merging with sibling. fill returns new index of child.
+func (n *BNode[T]) fill(i, d int) int {
+	c := n.Children[i]
+
+	if i > 0 && len(n.Children[i-1].Values) >= d {
+		l := n.Children[i-1]
+		c.Values = insertAt(c.Values, 0, n.Values[i-1])
+
+		last := len(l.Values) - 1
+		n.Values[i-1] = l.Values[last]
+		l.Values = removeAt(l.Values, last)
+
+		if !c.leaf() {
+			c.Children = insertAt(c.Children, 0, l.Children[last+1])
+			l.Children = removeAt(l.Children, last+1)
+		}
+
+		return i
+	}
+
+	if i < len(n.Values) && len(n.Children[i+1].Values) >= d {
+		r := n.Children[i+1]
+		c.Values = append(c.Values, n.Values[i])
+
+		n.Values[i] = r.Values[0]
+		r.Values = removeAt(r.Values, 0)
+
+		if !c.leaf() {
+			c.Children = append(c.Children, r.Children[0])
+			r.Children = removeAt(r.Children, 0)
+		}
+
+		return i
+	}
+
+	if i == len(n.Values) {
+		i--
+	}
+
+	n.merge(i)
+	return i
+}
+
+// merge merges value i of n and child i+1 into child i.
+func (n *BNode[T]) merge(i int) {
+	c, r := n.Children[i], n.Children[i+1]
+
+	c.Values = append(c.Values, n.Values[i])
+	c.Values = append(c.Values, r.Values...)
+	if !c.leaf() {
+		c.Children = append(c.Children, r.Children...)
+	}
+
+	n.Values = removeAt(n.Values, i)
+	n.Children = removeAt(n.Children, i+1)
+}
+
+func (n *BNode[T]) min() T {
+	for !n.leaf() {
+		n = n.Children[0]
+	}
+
+	return n.Values[0]
+}
+
+func (n *BNode[T]) max() T {
+	for !n.leaf() {
+		n = n.Children[len(n.Children)-1]
+	}
+
+	return n.Values[len(n.Values)-1]
+}
+
+func (n *BNode[T]) ascend(fn func(v T) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	for i, v := range n.Values {
+		if !n.leaf() && !n.Children[i].ascend(fn) {
+			return false
+		}
+
+		if !fn(v) {
+			return false
+		}
+	}
+
+	if !n.leaf() {
+		return n.Children[len(n.Children)-1].ascend(fn)
+	}
+
+	return true
+}
+
+func insertAt[E any](s []E, i int, e E) []E {
+	var zero E
+	s = append(s, zero)
+	copy(s[i+1:], s[i:])
+	s[i] = e
+
+	return s
+}
+
+func removeAt[E any](s []E, i int) []E {
+	var zero E
+	copy(s[i:], s[i+1:])
+	s[len(s)-1] = zero
+
+	return s[:len(s)-1]
+}
+
+func clearValues[E any](s []E) {
+	var zero E
+	for i := range s {
+		s[i] = zero
+	}
+}
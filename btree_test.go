@@ -0,0 +1,53 @@
+package rbt_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"gotest.com/rbt"
+)
+
+func TestBTree(t *testing.T) {
+	for _, d := range []int{2, 3, 8} {
+		tree := &rbt.BTree[int]{Degree: d}
+		vs := rand.Perm(2000)
+
+		for _, v := range vs {
+			tree.Insert(v)
+		}
+
+		err := checkBTree(tree, d)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v, ok := tree.Find(1234); !ok || v != 1234 {
+			t.Fatal("value is not found")
+		}
+
+		for _, v := range vs[:1000] {
+			if !tree.Delete(v) {
+				t.Fatal("value is not deleted", v)
+			}
+		}
+
+		err = checkBTree(tree, d)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		prev := -1
+		tree.Ascend(func(v int) bool {
+			if v <= prev {
+				t.Fatal("wrong order", prev, v)
+			}
+
+			prev = v
+			return true
+		})
+
+		if tree.Len() != 1000 || tree.Height() < 2 {
+			t.Fatal("unexpected len or height", tree.Len(), tree.Height())
+		}
+	}
+}
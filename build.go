@@ -0,0 +1,229 @@
+package rbt
+
+import (
+	"constraints"
+	"runtime"
+	"sync"
+
+	"gotest.com/rbt/compare"
+)
+
+// BuildSorted returns tree with values in O(n), values must be sorted in
+// ascending order. Tree is perfectly balanced, nodes of the last level are
+// red if the level is not full, all other nodes are black.
+func BuildSorted[T constraints.Ordered](values []T) *Tree[T] {
+	t := &Tree[T]{}
+	t.build(values)
+
+	return t
+}
+
+// build replaces all nodes of t with nodes of sorted values in O(n).
+func (t *Tree[T]) build(values []T) {
+	// old nodes are not attached to t anymore, see attached
+	t.owner = &token{}
+	t.Root = t.buildNode(values, nil, 0, redDepth(len(values)))
+	t.count = len(values)
+	t.min, t.max = t.Root.Min(), t.Root.Max()
+}
+
+// buildNode builds subtree of values with parent p at depth d, nodes at
+// red depth are red.
+func (t *Tree[T]) buildNode(values []T, p *Node[T], d, red int) *Node[T] {
+	if len(values) == 0 {
+		return nil
+	}
+
+	m := len(values) / 2
+	n := &Node[T]{
+		Value:  values[m],
+		Parent: p,
+		Red:    d == red,
+		owner:  t.owner,
+		hash:   t.hashOf(values[m]),
+	}
+
+	n.Left = t.buildNode(values[:m], n, d+1, red)
+	n.Right = t.buildNode(values[m+1:], n, d+1, red)
+	n.update()
+
+	return n
+}
+
+// build replaces all nodes of t with nodes of sorted values in O(n).
+func (t *TreeCmp[T]) build(values []T) {
+	// old nodes are not attached to t anymore, see Tree.attached
+	t.owner = &token{}
+	t.Root = t.buildNode(values, nil, 0, redDepth(len(values)))
+	t.count = len(values)
+	t.min, t.max = t.Root.Min(), t.Root.Max()
+}
+
+// buildNode builds subtree of values with parent p at depth d, nodes at
+// red depth are red.
+func (t *TreeCmp[T]) buildNode(values []T, p *NodeCmp[T], d, red int) *NodeCmp[T] {
+	if len(values) == 0 {
+		return nil
+	}
+
+	m := len(values) / 2
+	n := &NodeCmp[T]{
+		Value:  values[m],
+		Parent: p,
+		Red:    d == red,
+		owner:  t.owner,
+		hash:   t.hashOf(values[m]),
+	}
+
+	n.Left = t.buildNode(values[:m], n, d+1, red)
+	n.Right = t.buildNode(values[m+1:], n, d+1, red)
+	n.update()
+
+	return n
+}
+
+// redDepth returns depth of red nodes in perfectly balanced tree of n
+// nodes, that is the last level if it is not full, or -1.
+func redDepth(n int) int {
+	// last level is full, so all nodes can be black
+	if (n+1)&n == 0 {
+		return -1
+	}
+
+	d := 0
+	for ; n > 1; n /= 2 {
+		d++
+	}
+
+	return d
+}
+
+// BuildParallel returns tree with values that are not sorted. Values are
+// copied and sorted in chunks by workers concurrently, chunks are merged
+// in pairs concurrently, then tree is built by BuildSorted. Zero or
+// negative workers means GOMAXPROCS. Sorting is stable, so result does
+// not depend on number of workers.
+func BuildParallel[T constraints.Ordered](values []T, workers int) *Tree[T] {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	if workers > len(values) {
+		workers = len(values)
+	}
+
+	vs := append([]T(nil), values...)
+	if workers <= 1 {
+		sortStable(vs, compare.Ordered[T])
+		return BuildSorted(vs)
+	}
+
+	chunks := make([][]T, workers)
+	for i := range chunks {
+		chunks[i] = vs[i*len(vs)/workers : (i+1)*len(vs)/workers]
+	}
+
+	parallel(len(chunks), func(i int) {
+		sortStable(chunks[i], compare.Ordered[T])
+	})
+
+	// merge neighbour chunks until one is left, chunks are merged in order
+	// and left value goes first on equality, so merge is stable too
+	buf := make([]T, len(vs))
+	for len(chunks) > 1 {
+		merged := make([][]T, (len(chunks)+1)/2)
+		off := make([]int, len(merged))
+		for i := 1; i < len(merged); i++ {
+			off[i] = off[i-1] + len(chunks[2*i-2]) + len(chunks[2*i-1])
+		}
+
+		parallel(len(merged), func(i int) {
+			a := chunks[2*i]
+			var b []T
+			if 2*i+1 < len(chunks) {
+				b = chunks[2*i+1]
+			}
+
+			merged[i] = merge(buf[off[i]:off[i]+len(a)+len(b)], a, b, compare.Ordered[T])
+		})
+
+		chunks = merged
+		vs, buf = buf, vs
+	}
+
+	return BuildSorted(chunks[0])
+}
+
+// sortStable sorts vs by cmp with insertion sort of small runs and merging of them.
+func sortStable[T any](vs []T, cmp func(a, b T) int) {
+	const run = 32
+
+	for i := 0; i < len(vs); i += run {
+		r := vs[i:]
+		if len(r) > run {
+			r = r[:run]
+		}
+
+		for j := 1; j < len(r); j++ {
+			for k := j; k > 0 && cmp(r[k], r[k-1]) < 0; k-- {
+				r[k], r[k-1] = r[k-1], r[k]
+			}
+		}
+	}
+
+	src, dst := vs, make([]T, len(vs))
+	for w := run; w < len(vs); w *= 2 {
+		for i := 0; i < len(vs); i += 2 * w {
+			m, e := i+w, i+2*w
+			if m > len(vs) {
+				m = len(vs)
+			}
+
+			if e > len(vs) {
+				e = len(vs)
+			}
+
+			merge(dst[i:e], src[i:m], src[m:e], cmp)
+		}
+
+		src, dst = dst, src
+	}
+
+	copy(vs, src)
+}
+
+// merge merges a and b sorted by cmp into dst and returns dst.
+func merge[T any](dst, a, b []T, cmp func(a, b T) int) []T {
+	i, j, k := 0, 0, 0
+	for i < len(a) && j < len(b) {
+		if cmp(b[j], a[i]) < 0 {
+			dst[k] = b[j]
+			j++
+		} else {
+			dst[k] = a[i]
+			i++
+		}
+
+		k++
+	}
+
+	k += copy(dst[k:], a[i:])
+	copy(dst[k:], b[j:])
+
+	return dst
+}
+
+// parallel calls fn for 0..n-1 concurrently and waits for all of them.
+func parallel(n int, fn func(i int)) {
+	wg := sync.WaitGroup{}
+	wg.Add(n)
+
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			fn(i)
+		}(i)
+	}
+
+	wg.Wait()
+}
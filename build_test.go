@@ -0,0 +1,93 @@
+package rbt_test
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"gotest.com/rbt"
+)
+
+func TestBuildSorted(t *testing.T) {
+	for n := 0; n < 100; n++ {
+		vs := make([]int, n)
+		for i := range vs {
+			vs[i] = i / 2
+		}
+
+		tree := rbt.BuildSorted(vs)
+		if err := checkTree(tree.Root); err != nil {
+			t.Fatal(n, err)
+		}
+
+		if got := treeValues(tree); tree.Len() != n || n > 0 && !reflect.DeepEqual(got, vs) {
+			t.Fatal("unexpected values", got)
+		}
+
+		// built tree is usable as any other one
+		tree.Insert(-1)
+		tree.Delete(n / 4)
+		if err := checkTree(tree.Root); err != nil {
+			t.Fatal(n, err)
+		}
+
+		if min, _ := tree.Min(); min != -1 || tree.HeadSet(n).Len() != tree.Len() {
+			t.Fatal("unexpected min or len", min, tree.HeadSet(n).Len())
+		}
+	}
+}
+
+func TestBuildParallel(t *testing.T) {
+	vs := make([]int, 10000)
+	for i := range vs {
+		vs[i] = rand.Intn(1000)
+	}
+
+	orig := append([]int{}, vs...)
+	expected := append([]int{}, vs...)
+	sort.Ints(expected)
+
+	var first *rbt.Tree[int]
+	for _, workers := range []int{0, 1, 3, 8, 20000} {
+		tree := rbt.BuildParallel(vs, workers)
+		if err := checkTree(tree.Root); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := treeValues(tree); !reflect.DeepEqual(got, expected) {
+			t.Fatal("unexpected values with workers", workers)
+		}
+
+		if first == nil {
+			first = tree
+		} else if !rbt.StructurallyEqual(first, tree) {
+			t.Fatal("tree depends on number of workers", workers)
+		}
+	}
+
+	if !reflect.DeepEqual(vs, orig) {
+		t.Fatal("input is changed")
+	}
+}
+
+func BenchmarkBuildParallel(b *testing.B) {
+	vs := rand.Perm(1 << 20)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		rbt.BuildParallel(vs, 0)
+	}
+}
+
+func BenchmarkBuildInsert(b *testing.B) {
+	vs := rand.Perm(1 << 20)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tree := &rbt.Tree[int]{}
+		for _, v := range vs {
+			tree.Insert(v)
+		}
+	}
+}
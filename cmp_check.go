@@ -0,0 +1,86 @@
+package rbt
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNilCmp is panic value of TreeCmp that is used without Cmp.
+	ErrNilCmp = errors.New("Cmp of TreeCmp is nil")
+	// ErrInconsistentCmp is wrapped by errors of comparator that is not
+	// antisymmetric or not transitive, see TreeCmp.Debug.
+	ErrInconsistentCmp = errors.New("inconsistent comparator")
+)
+
+// Err returns the first inconsistency of Cmp that is found in debug mode
+// or nil. In debug mode every call of Cmp is repeated with swapped
+// arguments to check that results are opposite, and inserted value is
+// compared with all values of t to check that they are ordered, that
+// makes Insert O(n). Tree with inconsistent comparator can lose values.
+func (t *TreeCmp[T]) Err() error {
+	return t.err
+}
+
+// cmp returns comparator of t that checks Cmp in debug mode.
+func (t *TreeCmp[T]) cmp() func(a, b T) int {
+	if t.Cmp == nil {
+		panic(ErrNilCmp)
+	}
+
+	if t.Debug {
+		return t.checkedCmp
+	}
+
+	return t.Cmp
+}
+
+// checkedCmp compares a and b with Cmp and checks that Cmp is antisymmetric.
+func (t *TreeCmp[T]) checkedCmp(a, b T) int {
+	c := t.Cmp(a, b)
+	if r := t.Cmp(b, a); sign(c) != -sign(r) {
+		t.report(fmt.Errorf("%w: cmp(%v, %v) = %d, cmp(%v, %v) = %d", ErrInconsistentCmp, a, b, c, b, a, r))
+	}
+
+	return c
+}
+
+// checkOrder checks that n is ordered with all values of t. Values are
+// ordered in tree if Cmp is transitive.
+func (t *TreeCmp[T]) checkOrder(n *NodeCmp[T]) {
+	if c := t.Cmp(n.Value, n.Value); c != 0 {
+		t.report(fmt.Errorf("%w: cmp(%v, %v) = %d", ErrInconsistentCmp, n.Value, n.Value, c))
+	}
+
+	before := true
+	var it iterCmp[T]
+	for m := it.min(t.Root); m != nil; m = it.next() {
+		if m == n {
+			before = false
+			continue
+		}
+
+		c := t.Cmp(m.Value, n.Value)
+		if before && c > 0 || !before && c < 0 {
+			t.report(fmt.Errorf("%w: %v and %v are out of order, cmp = %d", ErrInconsistentCmp, m.Value, n.Value, c))
+			return
+		}
+	}
+}
+
+// report saves err if it is the first error.
+func (t *TreeCmp[T]) report(err error) {
+	if t.err == nil {
+		t.err = err
+	}
+}
+
+func sign(c int) int {
+	if c < 0 {
+		return -1
+	} else if c > 0 {
+		return 1
+	}
+
+	return 0
+}
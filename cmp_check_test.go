@@ -0,0 +1,180 @@
+package rbt_test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"gotest.com/rbt"
+	"gotest.com/rbt/compare"
+)
+
+func TestTreeNaN(t *testing.T) {
+	tree := &rbt.Tree[float64]{}
+	for _, v := range []float64{3, math.NaN(), 1, math.Inf(-1), math.NaN(), 2} {
+		tree.Insert(v)
+	}
+
+	if !tree.Contains(math.NaN()) || !tree.Contains(2) || !tree.Contains(math.Inf(-1)) {
+		t.Fatal("value is not found")
+	}
+
+	// NaN is less than any other value
+	if min, _ := tree.Min(); !math.IsNaN(min) {
+		t.Fatal("unexpected min", min)
+	}
+
+	if !tree.Delete(math.NaN()) || !tree.Delete(math.NaN()) || tree.Delete(math.NaN()) {
+		t.Fatal("unexpected delete of NaN")
+	}
+
+	if got := treeValues(tree); !reflect.DeepEqual(got, []float64{math.Inf(-1), 1, 2, 3}) {
+		t.Fatal("unexpected values", got)
+	}
+
+	if err := checkTree(tree.Root); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNaNOrder(t *testing.T) {
+	nan := math.NaN()
+
+	a := &rbt.Tree[float64]{}
+	for _, v := range []float64{nan, 1, 2, 3} {
+		a.Insert(v)
+	}
+
+	b := a.Clone()
+	b.Delete(2)
+
+	changes := 0
+	rbt.Diff(a, b, func(op rbt.DiffOp, v float64) {
+		if op != rbt.Removed || v != 2 {
+			t.Fatal("unexpected change", op, v)
+		}
+
+		changes++
+	})
+
+	if changes != 1 {
+		t.Fatal("unexpected changes", changes)
+	}
+
+	c := &rbt.Tree[float64]{}
+	for _, v := range []float64{nan, 1, 2, 3} {
+		c.Insert(v)
+	}
+
+	if !rbt.StructurallyEqual(a, c) {
+		t.Fatal("trees with NaN are not equal")
+	}
+
+	if got := a.HeadSet(2).HeadSet(nan).Len(); got != 0 {
+		t.Fatal("head set of NaN is not empty", got)
+	}
+
+	if got := a.TailSet(nan).TailSet(1).Len(); got != 3 {
+		t.Fatal("unexpected tail set len", got)
+	}
+
+	s := &rbt.Sharded[float64]{MaxShardLen: 4}
+	for _, v := range []float64{nan, 3, nan, 1, 2, 0, nan, 4} {
+		s.Insert(v)
+	}
+
+	if !s.Contains(nan) || s.Len() != 8 {
+		t.Fatal("NaN is lost in sharded set", s.Len())
+	}
+
+	tb := rbt.NewTreeBy(func(v float64) float64 { return v })
+	tb.Insert(1)
+	tb.Insert(nan)
+	if !tb.Contains(nan) || !tb.Delete(nan) || tb.Len() != 1 {
+		t.Fatal("NaN key is not found")
+	}
+
+	m := &rbt.MVCC[float64]{}
+	m.Insert(1)
+	m.Insert(nan)
+	if _, ok := m.Delete(nan); !ok {
+		t.Fatal("NaN is not deleted from MVCC")
+	}
+}
+
+func TestTreeCmpNilCmp(t *testing.T) {
+	defer func() {
+		if r := recover(); r != rbt.ErrNilCmp {
+			t.Fatal("unexpected panic", r)
+		}
+	}()
+
+	tree := &rbt.TreeCmp[int]{}
+	tree.Insert(1)
+}
+
+func TestTreeCmpNilCmpComparable(t *testing.T) {
+	defer func() {
+		if r := recover(); r != rbt.ErrNilCmp {
+			t.Fatal("unexpected panic", r)
+		}
+	}()
+
+	// values with Compare method are not compared by it without Cmp
+	tree := &rbt.TreeCmp[version]{}
+	tree.Insert(version{1, 0, 0})
+	tree.Insert(version{2, 0, 0})
+}
+
+func TestNewTreeCmpNilCmp(t *testing.T) {
+	defer func() {
+		if r := recover(); r != rbt.ErrNilCmp {
+			t.Fatal("unexpected panic", r)
+		}
+	}()
+
+	rbt.NewTreeCmp[int](nil)
+}
+
+func TestTreeCmpDebug(t *testing.T) {
+	tree := rbt.NewTreeCmp(compare.Ordered[int])
+	tree.Debug = true
+	for i := 0; i < 100; i++ {
+		tree.Insert(i % 7)
+	}
+
+	if err := tree.Err(); err != nil {
+		t.Fatal("consistent comparator is reported", err)
+	}
+
+	// a-b is not antisymmetric when it overflows
+	tree = rbt.NewTreeCmp(func(a, b int) int { return a - b })
+	tree.Debug = true
+	tree.Insert(math.MinInt)
+	tree.Insert(0)
+
+	if err := tree.Err(); !errors.Is(err, rbt.ErrInconsistentCmp) {
+		t.Fatal("overflow is not reported", err)
+	}
+
+	// rock beats scissors, scissors beats paper, paper beats rock
+	beats := map[string]string{"rock": "scissors", "scissors": "paper", "paper": "rock"}
+	rps := rbt.NewTreeCmp(func(a, b string) int {
+		if a == b {
+			return 0
+		} else if beats[a] == b {
+			return 1
+		}
+
+		return -1
+	})
+	rps.Debug = true
+	rps.Insert("rock")
+	rps.Insert("paper")
+	rps.Insert("scissors")
+
+	if err := rps.Err(); !errors.Is(err, rbt.ErrInconsistentCmp) {
+		t.Fatal("non-transitive comparator is not reported", err)
+	}
+}
@@ -0,0 +1,80 @@
+package rbt
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ErrValueTooLong is returned by DiskTree and Durable for value that does
+// not fit encoded size of codec.
+var ErrValueTooLong = errors.New("value is too long for codec")
+
+// Codec encodes values to fixed size binary form, it is used to store
+// values in files. Codec that cannot encode some values can also have method
+// Check(v T) error, DiskTree and Durable reject values it returns error for.
+type Codec[T any] interface {
+	// Size returns size of encoded value in bytes.
+	Size() int
+	// Encode writes v to b, len(b) is equal to Size.
+	Encode(b []byte, v T)
+	// Decode reads value from b, len(b) is equal to Size.
+	Decode(b []byte) T
+}
+
+// Int64Codec encodes int64 values.
+type Int64Codec struct{}
+
+func (Int64Codec) Size() int {
+	return 8
+}
+
+func (Int64Codec) Encode(b []byte, v int64) {
+	binary.LittleEndian.PutUint64(b, uint64(v))
+}
+
+func (Int64Codec) Decode(b []byte) int64 {
+	return int64(binary.LittleEndian.Uint64(b))
+}
+
+// StringCodec encodes strings up to Len bytes. Encode truncates longer
+// strings, DiskTree and Durable reject them with ErrValueTooLong.
+type StringCodec struct {
+	Len int
+}
+
+func (c StringCodec) Size() int {
+	return c.Len + 2
+}
+
+func (c StringCodec) Encode(b []byte, v string) {
+	if len(v) > c.Len {
+		v = v[:c.Len]
+	}
+
+	binary.LittleEndian.PutUint16(b, uint16(len(v)))
+	n := copy(b[2:], v)
+	clearValues(b[2+n:])
+}
+
+// Check returns ErrValueTooLong if v is longer than Len bytes.
+func (c StringCodec) Check(v string) error {
+	if len(v) > c.Len {
+		return ErrValueTooLong
+	}
+
+	return nil
+}
+
+func (c StringCodec) Decode(b []byte) string {
+	l := int(binary.LittleEndian.Uint16(b))
+	return string(b[2 : 2+l])
+}
+
+// checkValue returns error of Check method of c if it has one.
+func checkValue[T any](c Codec[T], v T) error {
+	if ch, ok := c.(interface{ Check(v T) error }); ok {
+		return ch.Check(v)
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+// Package compare provides comparators for rbt.TreeCmp. Comparators for
+// struct keys are built from fields:
+//
+//	cmp := compare.By(func(u User) string { return u.Name }).
+//		Then(compare.ByTime(func(u User) time.Time { return u.Created }).Reverse())
+//
+// All comparators return negative number if a is less than b, zero if they
+// are equal and positive number otherwise. They never subtract values, so
+// they do not overflow.
+package compare
+
+import (
+	"constraints"
+	"time"
+)
+
+// Func compares a and b.
+type Func[T any] func(a, b T) int
+
+// Ordered compares a and b with < and >. Floats are ordered totally,
+// NaN is less than any other value and is equal to NaN.
+func Ordered[T constraints.Ordered](a, b T) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	} else if a == b {
+		return 0
+	}
+
+	// a or b is NaN
+	an, bn := a != a, b != b
+	if an && bn {
+		return 0
+	} else if an {
+		return -1
+	}
+
+	return 1
+}
+
+// Time compares a and b as instants of time.
+func Time(a, b time.Time) int {
+	if a.Before(b) {
+		return -1
+	} else if a.After(b) {
+		return 1
+	}
+
+	return 0
+}
+
+// By returns comparator of values by their field.
+func By[T any, F constraints.Ordered](field func(v T) F) Func[T] {
+	return func(a, b T) int {
+		return Ordered(field(a), field(b))
+	}
+}
+
+// ByTime returns comparator of values by their time field.
+func ByTime[T any](field func(v T) time.Time) Func[T] {
+	return func(a, b T) int {
+		return Time(field(a), field(b))
+	}
+}
+
+// ByFunc returns comparator of values by their field compared with cmp.
+func ByFunc[T, F any](field func(v T) F, cmp func(a, b F) int) Func[T] {
+	return func(a, b T) int {
+		return cmp(field(a), field(b))
+	}
+}
+
+// Then returns comparator that compares with next if values are equal by f.
+func (f Func[T]) Then(next func(a, b T) int) Func[T] {
+	return func(a, b T) int {
+		if c := f(a, b); c != 0 {
+			return c
+		}
+
+		return next(a, b)
+	}
+}
+
+// Reverse returns comparator with reversed order of f.
+func (f Func[T]) Reverse() Func[T] {
+	return Reverse[T](f)
+}
+
+// Reverse returns comparator with reversed order of cmp.
+func Reverse[T any](cmp func(a, b T) int) Func[T] {
+	return func(a, b T) int {
+		return cmp(b, a)
+	}
+}
+
+// NilsFirst returns comparator of pointers that orders nil before all
+// other pointers and compares values of other pointers with cmp.
+func NilsFirst[T any](cmp func(a, b T) int) Func[*T] {
+	return func(a, b *T) int {
+		if a == nil || b == nil {
+			return nilCompare(a == nil, b == nil)
+		}
+
+		return cmp(*a, *b)
+	}
+}
+
+// NilsLast returns comparator of pointers that orders nil after all
+// other pointers and compares values of other pointers with cmp.
+func NilsLast[T any](cmp func(a, b T) int) Func[*T] {
+	return func(a, b *T) int {
+		if a == nil || b == nil {
+			return -nilCompare(a == nil, b == nil)
+		}
+
+		return cmp(*a, *b)
+	}
+}
+
+// nilCompare compares values where nil is the least.
+func nilCompare(anil, bnil bool) int {
+	if anil && bnil {
+		return 0
+	} else if anil {
+		return -1
+	}
+
+	return 1
+}
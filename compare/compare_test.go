@@ -0,0 +1,116 @@
+package compare_test
+
+import (
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"gotest.com/rbt"
+	"gotest.com/rbt/compare"
+)
+
+type user struct {
+	name    string
+	age     int
+	created time.Time
+}
+
+func TestOrderedOverflow(t *testing.T) {
+	// a-b overflows for these values
+	if compare.Ordered(math.MinInt64, 1) >= 0 || compare.Ordered(math.MaxInt64, -1) <= 0 {
+		t.Fatal("wrong order of extreme ints")
+	}
+
+	if compare.Ordered(int8(-128), int8(127)) >= 0 || compare.Ordered("a", "a") != 0 {
+		t.Fatal("wrong order")
+	}
+
+	nan := math.NaN()
+	if compare.Ordered(nan, math.Inf(-1)) >= 0 || compare.Ordered(1.0, nan) <= 0 || compare.Ordered(nan, nan) != 0 {
+		t.Fatal("wrong order of NaN")
+	}
+}
+
+func TestComposite(t *testing.T) {
+	t0 := time.Unix(0, 0)
+	users := []user{
+		{"bob", 30, t0.Add(time.Hour)},
+		{"alice", 30, t0},
+		{"bob", 20, t0.Add(2 * time.Hour)},
+		{"alice", 40, t0.Add(3 * time.Hour)},
+		{"bob", 20, t0},
+	}
+
+	cmp := compare.By(func(u user) string { return u.name }).
+		Then(compare.By(func(u user) int { return u.age }).Reverse()).
+		Then(compare.ByTime(func(u user) time.Time { return u.created }))
+
+	sort.Slice(users, func(i, j int) bool {
+		return cmp(users[i], users[j]) < 0
+	})
+
+	expected := []user{
+		{"alice", 40, t0.Add(3 * time.Hour)},
+		{"alice", 30, t0},
+		{"bob", 30, t0.Add(time.Hour)},
+		{"bob", 20, t0},
+		{"bob", 20, t0.Add(2 * time.Hour)},
+	}
+
+	for i := range users {
+		if cmp(users[i], expected[i]) != 0 {
+			t.Fatal("unexpected order", users)
+		}
+	}
+}
+
+func TestNils(t *testing.T) {
+	one, two := 1, 2
+
+	first := compare.NilsFirst(compare.Ordered[int])
+	if first(nil, &one) >= 0 || first(&two, nil) <= 0 || first(nil, nil) != 0 || first(&one, &two) >= 0 {
+		t.Fatal("wrong order of nils first")
+	}
+
+	last := compare.NilsLast(compare.Ordered[int])
+	if last(nil, &one) <= 0 || last(&two, nil) >= 0 || last(nil, nil) != 0 || last(&one, &two) >= 0 {
+		t.Fatal("wrong order of nils last")
+	}
+
+	byFirst := compare.ByFunc(func(s []int) *int {
+		if len(s) == 0 {
+			return nil
+		}
+
+		return &s[0]
+	}, first)
+	if byFirst(nil, []int{1}) >= 0 {
+		t.Fatal("wrong order of empty slice")
+	}
+}
+
+func TestTreeCmp(t *testing.T) {
+	tree := rbt.NewTreeCmp(compare.By(func(u user) string { return u.name }).
+		Then(compare.By(func(u user) int { return u.age })))
+
+	tree.Insert(user{name: "bob", age: math.MaxInt})
+	tree.Insert(user{name: "bob", age: math.MinInt})
+	tree.Insert(user{name: "alice", age: 1})
+
+	names := []string{}
+	ages := []int{}
+	tree.Ascend(func(u user) bool {
+		names = append(names, u.name)
+		ages = append(ages, u.age)
+		return true
+	})
+
+	if names[0] != "alice" || ages[1] != math.MinInt || ages[2] != math.MaxInt {
+		t.Fatal("unexpected order", names, ages)
+	}
+
+	if !tree.Contains(user{name: "bob", age: math.MinInt}) {
+		t.Fatal("value is not found")
+	}
+}
@@ -0,0 +1,259 @@
+package rbt
+
+import (
+	"constraints"
+	"context"
+
+	"gotest.com/rbt/compare"
+)
+
+// ctxCheckEvery is number of values between checks of context cancellation.
+const ctxCheckEvery = 256
+
+// AscendContext calls fn for values of t in ascending order until fn returns
+// false. Context is checked periodically, its error is returned if it is
+// done before the end of t.
+func (t *Tree[T]) AscendContext(ctx context.Context, fn func(v T) bool) error {
+	return t.RangeContext(ctx, KeyRange[T]{LoInf: true, HiInf: true}, fn)
+}
+
+// RangeContext calls fn for values in range r in ascending order until fn
+// returns false. Context is checked periodically, its error is returned
+// if it is done before the end of range.
+func (t *Tree[T]) RangeContext(ctx context.Context, r KeyRange[T], fn func(v T) bool) error {
+	var it iter[T]
+	n := it.min(t.Root)
+	if !r.LoInf {
+		n = it.lowerBound(t.Root, r.Lo)
+	}
+
+	for i := 0; n != nil && (r.HiInf || compare.Ordered(n.Value, r.Hi) < 0); i, n = i+1, it.next() {
+		if i%ctxCheckEvery == 0 && ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if !fn(n.Value) {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// AscendContext calls fn for values of t in ascending order until fn returns
+// false. Context is checked periodically, its error is returned if it is
+// done before the end of t.
+func (t *TreeCmp[T]) AscendContext(ctx context.Context, fn func(v T) bool) error {
+	return t.RangeContext(ctx, KeyRange[T]{LoInf: true, HiInf: true}, fn)
+}
+
+// RangeContext calls fn for values in range r in ascending order until fn
+// returns false. Context is checked periodically, its error is returned
+// if it is done before the end of range.
+func (t *TreeCmp[T]) RangeContext(ctx context.Context, r KeyRange[T], fn func(v T) bool) error {
+	cmp := t.cmp()
+
+	var it iterCmp[T]
+	n := it.min(t.Root)
+	if !r.LoInf {
+		n = it.lowerBound(t.Root, r.Lo, cmp)
+	}
+
+	for i := 0; n != nil && (r.HiInf || cmp(n.Value, r.Hi) < 0); i, n = i+1, it.next() {
+		if i%ctxCheckEvery == 0 && ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if !fn(n.Value) {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// Cursor iterates values of Tree in ascending order. Cursor keeps the last
+// returned value rather than node, so it continues after it even if the
+// tree was changed between calls, values inserted after the last value
+// are seen by cursor. Every call of Next finds position in O(log n + d)
+// where d is number of returned values equal to the last one, so long runs
+// of equal values are read faster by Scan.
+type Cursor[T constraints.Ordered] struct {
+	tree    *Tree[T]
+	last    T
+	dup     int // number of returned values equal to last, -1 means all of them
+	started bool
+}
+
+// Cursor returns cursor that starts from min value of t.
+func (t *Tree[T]) Cursor() *Cursor[T] {
+	return &Cursor[T]{tree: t}
+}
+
+// CursorAfter returns cursor that starts from the first value greater than
+// last, so scan can be resumed by the last seen value.
+func (t *Tree[T]) CursorAfter(last T) *Cursor[T] {
+	return &Cursor[T]{
+		tree:    t,
+		last:    last,
+		dup:     -1,
+		started: true,
+	}
+}
+
+// Last returns the last value returned by c or false if c is not started.
+func (c *Cursor[T]) Last() (T, bool) {
+	return c.last, c.started
+}
+
+// Next returns next value or false if there are no more values.
+func (c *Cursor[T]) Next() (T, bool) {
+	var it iter[T]
+	n := c.next(&it)
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+
+	c.advance(n.Value)
+
+	return n.Value, true
+}
+
+// Scan calls fn for values from current position of c until fn returns
+// false. Tree must not be changed by fn. Context is checked periodically,
+// its error is returned if it is done before the end of tree, then c is
+// positioned after the last value passed to fn.
+func (c *Cursor[T]) Scan(ctx context.Context, fn func(v T) bool) error {
+	var it iter[T]
+	for i, n := 0, c.next(&it); n != nil; i, n = i+1, it.next() {
+		if i%ctxCheckEvery == 0 && ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		c.advance(n.Value)
+		if !fn(n.Value) {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// next moves it to node of next value.
+func (c *Cursor[T]) next(it *iter[T]) *Node[T] {
+	if !c.started {
+		return it.min(c.tree.Root)
+	}
+
+	// skip values equal to last that are returned already
+	n := it.lowerBound(c.tree.Root, c.last)
+	for i := 0; n != nil && compare.Ordered(n.Value, c.last) == 0 && (c.dup < 0 || i < c.dup); i++ {
+		n = it.next()
+	}
+
+	return n
+}
+
+func (c *Cursor[T]) advance(v T) {
+	if c.started && compare.Ordered(v, c.last) == 0 {
+		if c.dup >= 0 {
+			c.dup++
+		}
+
+		return
+	}
+
+	c.last, c.dup, c.started = v, 1, true
+}
+
+// CursorCmp iterates values of TreeCmp in ascending order, see Cursor.
+type CursorCmp[T any] struct {
+	tree    *TreeCmp[T]
+	last    T
+	dup     int // number of returned values equal to last, -1 means all of them
+	started bool
+}
+
+// Cursor returns cursor that starts from min value of t.
+func (t *TreeCmp[T]) Cursor() *CursorCmp[T] {
+	return &CursorCmp[T]{tree: t}
+}
+
+// CursorAfter returns cursor that starts from the first value greater than
+// last, so scan can be resumed by the last seen value.
+func (t *TreeCmp[T]) CursorAfter(last T) *CursorCmp[T] {
+	return &CursorCmp[T]{
+		tree:    t,
+		last:    last,
+		dup:     -1,
+		started: true,
+	}
+}
+
+// Last returns the last value returned by c or false if c is not started.
+func (c *CursorCmp[T]) Last() (T, bool) {
+	return c.last, c.started
+}
+
+// Next returns next value or false if there are no more values.
+func (c *CursorCmp[T]) Next() (T, bool) {
+	var it iterCmp[T]
+	n := c.next(&it)
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+
+	c.advance(n.Value)
+
+	return n.Value, true
+}
+
+// Scan calls fn for values from current position of c until fn returns
+// false. Tree must not be changed by fn. Context is checked periodically,
+// its error is returned if it is done before the end of tree, then c is
+// positioned after the last value passed to fn.
+func (c *CursorCmp[T]) Scan(ctx context.Context, fn func(v T) bool) error {
+	var it iterCmp[T]
+	for i, n := 0, c.next(&it); n != nil; i, n = i+1, it.next() {
+		if i%ctxCheckEvery == 0 && ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		c.advance(n.Value)
+		if !fn(n.Value) {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// next moves it to node of next value.
+func (c *CursorCmp[T]) next(it *iterCmp[T]) *NodeCmp[T] {
+	if !c.started {
+		return it.min(c.tree.Root)
+	}
+
+	// skip values equal to last that are returned already
+	cmp := c.tree.cmp()
+	n := it.lowerBound(c.tree.Root, c.last, cmp)
+	for i := 0; n != nil && cmp(n.Value, c.last) == 0 && (c.dup < 0 || i < c.dup); i++ {
+		n = it.next()
+	}
+
+	return n
+}
+
+func (c *CursorCmp[T]) advance(v T) {
+	if c.started && c.tree.cmp()(v, c.last) == 0 {
+		if c.dup >= 0 {
+			c.dup++
+		}
+
+		return
+	}
+
+	c.last, c.dup, c.started = v, 1, true
+}
@@ -0,0 +1,134 @@
+package rbt_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"gotest.com/rbt"
+	"gotest.com/rbt/compare"
+)
+
+func TestAscendContext(t *testing.T) {
+	tree := &rbt.Tree[int]{}
+	for i := 0; i < 10000; i++ {
+		tree.Insert(i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	n := 0
+	err := tree.AscendContext(ctx, func(v int) bool {
+		n++
+		if n == 1000 {
+			cancel()
+		}
+
+		return true
+	})
+
+	// cancellation is seen not later than on next check
+	if err != context.Canceled || n >= 2000 {
+		t.Fatal("scan is not cancelled", err, n)
+	}
+
+	n = 0
+	err = tree.RangeContext(context.Background(), rbt.KeyRange[int]{Lo: 10, Hi: 20}, func(v int) bool {
+		n++
+		return true
+	})
+
+	if err != nil || n != 10 {
+		t.Fatal("unexpected range", err, n)
+	}
+
+	cmpTree := rbt.NewTreeCmp(compare.Ordered[int])
+	for i := 0; i < 1000; i++ {
+		cmpTree.Insert(i)
+	}
+
+	if err := cmpTree.AscendContext(ctx, func(v int) bool { return true }); err != context.Canceled {
+		t.Fatal("scan of cancelled context is not stopped", err)
+	}
+}
+
+func TestCursor(t *testing.T) {
+	tree := &rbt.Tree[int]{}
+	for _, v := range []int{1, 2, 2, 2, 3, 5} {
+		tree.Insert(v)
+	}
+
+	c := tree.Cursor()
+	got := []int{}
+	for i := 0; i < 3; i++ {
+		v, _ := c.Next()
+		got = append(got, v)
+	}
+
+	// tree is changed between calls, cursor continues after the last value
+	tree.Delete(1)
+	tree.Insert(4)
+	tree.Insert(0)
+
+	for v, ok := c.Next(); ok; v, ok = c.Next() {
+		got = append(got, v)
+	}
+
+	if !reflect.DeepEqual(got, []int{1, 2, 2, 2, 3, 4, 5}) {
+		t.Fatal("unexpected values", got)
+	}
+
+	if last, ok := c.Last(); !ok || last != 5 {
+		t.Fatal("unexpected last", last)
+	}
+
+	// resume by key from another cursor
+	got = got[:0]
+	err := tree.CursorAfter(2).Scan(context.Background(), func(v int) bool {
+		got = append(got, v)
+		return true
+	})
+
+	if err != nil || !reflect.DeepEqual(got, []int{3, 4, 5}) {
+		t.Fatal("unexpected values", got, err)
+	}
+}
+
+func TestCursorScanCancel(t *testing.T) {
+	tree := rbt.NewTreeCmp(compare.Ordered[int])
+	for i := 0; i < 5000; i++ {
+		tree.Insert(i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := tree.Cursor()
+
+	seen := 0
+	err := c.Scan(ctx, func(v int) bool {
+		seen++
+		if v == 600 {
+			cancel()
+		}
+
+		return true
+	})
+
+	if err != context.Canceled {
+		t.Fatal("scan is not cancelled", err)
+	}
+
+	// the next scan continues after the last value passed to fn
+	last, _ := c.Last()
+	if last != seen-1 {
+		t.Fatal("unexpected last", last, seen)
+	}
+
+	err = c.Scan(context.Background(), func(v int) bool {
+		seen++
+		return true
+	})
+
+	if err != nil || seen != 5000 {
+		t.Fatal("unexpected scan", err, seen)
+	}
+}
@@ -0,0 +1,286 @@
+package rbt
+
+import (
+	"constraints"
+
+	"gotest.com/rbt/compare"
+)
+
+// DiffOp is kind of change reported by Diff.
+type DiffOp int
+
+const (
+	// Added means that value is present only in new tree.
+	Added DiffOp = iota + 1
+	// Removed means that value is present only in old tree.
+	Removed
+	// Changed means that values are equal by Cmp but differ otherwise.
+	Changed
+)
+
+// String returns name of op.
+func (op DiffOp) String() string {
+	switch op {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	}
+
+	return "unknown"
+}
+
+// Diff reports changes needed to turn tree a into tree b. fn is called
+// in value order for each value that is only in a (Removed) or only in b (Added).
+// Subtrees that are shared by a and b, for example when b is a Clone of a,
+// are skipped without walking, so Diff of clones takes O(d log n) where
+// d is number of changed nodes.
+func Diff[T constraints.Ordered](a, b *Tree[T], fn func(op DiffOp, v T)) {
+	wa, wb := newDiffWalk(a.Root), newDiffWalk(b.Root)
+	for {
+		x, y := diffNext(wa, wb)
+		if x == nil && y == nil {
+			return
+		}
+
+		if y == nil || (x != nil && compare.Ordered(x.Value, y.Value) < 0) {
+			fn(Removed, x.Value)
+			wa.pop()
+		} else if x == nil || compare.Ordered(y.Value, x.Value) < 0 {
+			fn(Added, y.Value)
+			wb.pop()
+		} else {
+			wa.pop()
+			wb.pop()
+		}
+	}
+}
+
+// DiffCmp reports changes needed to turn tree a into tree b, values are
+// ordered by a.Cmp. fn is called in value order for each value that is
+// only in a (Removed, new is zero value) or only in b (Added, old is zero value).
+// If eq is not nil values that are equal by Cmp but not by eq are reported
+// as Changed, that allows to diff trees used as maps ordered by key.
+// Shared subtrees are skipped like in Diff.
+func DiffCmp[T any](a, b *TreeCmp[T], eq func(old, new T) bool, fn func(op DiffOp, old, new T)) {
+	var zero T
+
+	wa, wb := newDiffWalkCmp(a.Root), newDiffWalkCmp(b.Root)
+	for {
+		x, y := diffNextCmp(wa, wb)
+		if x == nil && y == nil {
+			return
+		}
+
+		c := 0
+		if x == nil {
+			c = 1
+		} else if y == nil {
+			c = -1
+		} else {
+			c = a.Cmp(x.Value, y.Value)
+		}
+
+		if c < 0 {
+			fn(Removed, x.Value, zero)
+			wa.pop()
+		} else if c > 0 {
+			fn(Added, zero, y.Value)
+			wb.pop()
+		} else {
+			if eq != nil && !eq(x.Value, y.Value) {
+				fn(Changed, x.Value, y.Value)
+			}
+
+			wa.pop()
+			wb.pop()
+		}
+	}
+}
+
+// diffPart is a part of tree that is not walked yet by Diff. It is
+// subtree n or only value of n if its left subtree is already expanded.
+type diffPart[N any] struct {
+	n     N
+	rank  int // 2 * black height of n + 1 if n is red, it is less in children
+	value bool
+}
+
+// diffWalk walks values of Tree in order. Parts of tree that are not walked
+// yet are kept in stack and subtrees are expanded only when their values are
+// needed, so subtree shared with the other tree is skipped as a whole.
+type diffWalk[T constraints.Ordered] struct {
+	parts []diffPart[*Node[T]]
+}
+
+func newDiffWalk[T constraints.Ordered](root *Node[T]) *diffWalk[T] {
+	bh := 0
+	for n := root; n != nil; n = n.Left {
+		if n.Black() {
+			bh++
+		}
+	}
+
+	w := &diffWalk[T]{}
+	w.push(root, bh)
+
+	return w
+}
+
+// diffNext skips parts that are shared by wa and wb and expands subtrees
+// until both walks have values at top or are finished. It returns nodes
+// of these values. Subtree of higher rank is expanded first, it can contain
+// the other subtree, subtrees of equal ranks are not nested.
+func diffNext[T constraints.Ordered](wa, wb *diffWalk[T]) (*Node[T], *Node[T]) {
+	for {
+		x, y := wa.top(), wb.top()
+		if x != nil && y != nil && x.n == y.n && x.value == y.value {
+			wa.pop()
+			wb.pop()
+		} else if x != nil && !x.value && (y == nil || y.value || x.rank >= y.rank) {
+			wa.expand()
+		} else if y != nil && !y.value {
+			wb.expand()
+		} else {
+			return wa.node(), wb.node()
+		}
+	}
+}
+
+// push pushes subtree n with black height bh.
+func (w *diffWalk[T]) push(n *Node[T], bh int) {
+	if n == nil {
+		return
+	}
+
+	r := 2 * bh
+	if n.Red {
+		r++
+	}
+
+	w.parts = append(w.parts, diffPart[*Node[T]]{n: n, rank: r})
+}
+
+// expand replaces subtree at top with its right subtree, value and left subtree.
+func (w *diffWalk[T]) expand() {
+	p := w.parts[len(w.parts)-1]
+	w.pop()
+
+	bh := p.rank / 2
+	if p.n.Black() {
+		bh--
+	}
+
+	w.push(p.n.Right, bh)
+	w.parts = append(w.parts, diffPart[*Node[T]]{n: p.n, value: true})
+	w.push(p.n.Left, bh)
+}
+
+func (w *diffWalk[T]) top() *diffPart[*Node[T]] {
+	if len(w.parts) == 0 {
+		return nil
+	}
+
+	return &w.parts[len(w.parts)-1]
+}
+
+// node returns node of value at top or nil if w is finished.
+func (w *diffWalk[T]) node() *Node[T] {
+	if len(w.parts) == 0 {
+		return nil
+	}
+
+	return w.parts[len(w.parts)-1].n
+}
+
+func (w *diffWalk[T]) pop() {
+	w.parts = w.parts[:len(w.parts)-1]
+}
+
+// diffWalkCmp walks values of TreeCmp in order, see diffWalk.
+type diffWalkCmp[T any] struct {
+	parts []diffPart[*NodeCmp[T]]
+}
+
+func newDiffWalkCmp[T any](root *NodeCmp[T]) *diffWalkCmp[T] {
+	bh := 0
+	for n := root; n != nil; n = n.Left {
+		if n.Black() {
+			bh++
+		}
+	}
+
+	w := &diffWalkCmp[T]{}
+	w.push(root, bh)
+
+	return w
+}
+
+// diffNextCmp skips shared parts of wa and wb, see diffNext.
+func diffNextCmp[T any](wa, wb *diffWalkCmp[T]) (*NodeCmp[T], *NodeCmp[T]) {
+	for {
+		x, y := wa.top(), wb.top()
+		if x != nil && y != nil && x.n == y.n && x.value == y.value {
+			wa.pop()
+			wb.pop()
+		} else if x != nil && !x.value && (y == nil || y.value || x.rank >= y.rank) {
+			wa.expand()
+		} else if y != nil && !y.value {
+			wb.expand()
+		} else {
+			return wa.node(), wb.node()
+		}
+	}
+}
+
+// push pushes subtree n with black height bh.
+func (w *diffWalkCmp[T]) push(n *NodeCmp[T], bh int) {
+	if n == nil {
+		return
+	}
+
+	r := 2 * bh
+	if n.Red {
+		r++
+	}
+
+	w.parts = append(w.parts, diffPart[*NodeCmp[T]]{n: n, rank: r})
+}
+
+// expand replaces subtree at top with its right subtree, value and left subtree.
+func (w *diffWalkCmp[T]) expand() {
+	p := w.parts[len(w.parts)-1]
+	w.pop()
+
+	bh := p.rank / 2
+	if p.n.Black() {
+		bh--
+	}
+
+	w.push(p.n.Right, bh)
+	w.parts = append(w.parts, diffPart[*NodeCmp[T]]{n: p.n, value: true})
+	w.push(p.n.Left, bh)
+}
+
+func (w *diffWalkCmp[T]) top() *diffPart[*NodeCmp[T]] {
+	if len(w.parts) == 0 {
+		return nil
+	}
+
+	return &w.parts[len(w.parts)-1]
+}
+
+// node returns node of value at top or nil if w is finished.
+func (w *diffWalkCmp[T]) node() *NodeCmp[T] {
+	if len(w.parts) == 0 {
+		return nil
+	}
+
+	return w.parts[len(w.parts)-1].n
+}
+
+func (w *diffWalkCmp[T]) pop() {
+	w.parts = w.parts[:len(w.parts)-1]
+}
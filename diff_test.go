@@ -0,0 +1,130 @@
+package rbt_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"gotest.com/rbt"
+	"gotest.com/rbt/compare"
+)
+
+func TestDiff(t *testing.T) {
+	a := &rbt.Tree[int]{}
+	for i := 0; i < 100; i++ {
+		a.Insert(rand.Intn(200))
+	}
+
+	b := a.Clone()
+
+	rbt.Diff(a, b, func(op rbt.DiffOp, v int) {
+		t.Fatal("unexpected change in clone", op, v)
+	})
+
+	for i := 0; i < 20; i++ {
+		b.Delete(rand.Intn(200))
+		b.Insert(rand.Intn(200))
+	}
+
+	// apply diff to a copy of a and check that it becomes b
+	c := a.Clone()
+	rbt.Diff(a, b, func(op rbt.DiffOp, v int) {
+		switch op {
+		case rbt.Added:
+			c.Insert(v)
+		case rbt.Removed:
+			c.Delete(v)
+		default:
+			t.Fatal("unexpected op", op)
+		}
+	})
+
+	if !reflect.DeepEqual(treeValues(c), treeValues(b)) {
+		t.Fatal("diff applied to a does not give b")
+	}
+}
+
+func TestDiffCmp(t *testing.T) {
+	type kv struct {
+		k string
+		v int
+	}
+
+	cmp := func(a, b kv) int {
+		if a.k < b.k {
+			return -1
+		} else if a.k > b.k {
+			return 1
+		}
+
+		return 0
+	}
+
+	a := &rbt.TreeCmp[kv]{Cmp: cmp}
+	a.Insert(kv{"a", 1})
+	a.Insert(kv{"b", 2})
+	a.Insert(kv{"c", 3})
+
+	b := a.Clone()
+	b.Delete(kv{k: "a"})
+	b.Delete(kv{k: "b"})
+	b.Insert(kv{"b", 20})
+	b.Insert(kv{"d", 4})
+
+	type change struct {
+		op       rbt.DiffOp
+		old, new kv
+	}
+
+	changes := []change{}
+	rbt.DiffCmp(a, b, func(old, new kv) bool {
+		return old == new
+	}, func(op rbt.DiffOp, old, new kv) {
+		changes = append(changes, change{op, old, new})
+	})
+
+	expected := []change{
+		{rbt.Removed, kv{"a", 1}, kv{}},
+		{rbt.Changed, kv{"b", 2}, kv{"b", 20}},
+		{rbt.Added, kv{}, kv{"d", 4}},
+	}
+
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("unexpected changes %v", changes)
+	}
+}
+
+func TestDiffCmpSkipsShared(t *testing.T) {
+	calls := 0
+	a := rbt.NewTreeCmp(func(a, b int) int {
+		calls++
+		return compare.Ordered(a, b)
+	})
+
+	for i := 0; i < 10000; i++ {
+		a.Insert(i * 2)
+	}
+
+	b := a.Clone()
+	b.Insert(5001)
+	b.Delete(8000)
+
+	changes := []int{}
+	calls = 0
+	rbt.DiffCmp(a, b, nil, func(op rbt.DiffOp, old, new int) {
+		if op == rbt.Added {
+			changes = append(changes, new)
+		} else {
+			changes = append(changes, -old)
+		}
+	})
+
+	if !reflect.DeepEqual(changes, []int{5001, -8000}) {
+		t.Fatal("unexpected changes", changes)
+	}
+
+	// only values of copied paths are compared
+	if calls > 200 {
+		t.Fatal("shared subtrees are walked", calls)
+	}
+}
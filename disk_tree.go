@@ -0,0 +1,701 @@
+package rbt
+
+import (
+	"constraints"
+	"container/list"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gotest.com/rbt/compare"
+)
+
+const (
+	// PageSize is size of page of DiskTree file.
+	PageSize = 4096
+	// DefaultCachePages is number of pages cached by DiskTree by default.
+	DefaultCachePages = 64
+
+	diskMagic = "RBTDISK1"
+
+	// header page layout
+	hdrValueSize = 8
+	hdrRoot      = 16
+	hdrCount     = 24
+	hdrNext      = 32
+	hdrFree      = 40
+
+	// node record layout, value goes after flags
+	recLeft   = 0
+	recRight  = 8
+	recParent = 16
+	recFlags  = 24
+	recValue  = 25
+)
+
+// ErrClosed is returned by methods of closed DiskTree.
+var ErrClosed = errors.New("tree is closed")
+
+// DiskTree represents red-black tree stored in a file. Nodes are records of
+// fixed size in pages of PageSize, node is referenced by its number, zero
+// number is nil node. Only bounded number of pages is kept in memory.
+// Changes are written to the file when page is evicted from cache and by Sync
+// and Close, so the tree survives restart only after Sync or Close. DiskTree
+// is not crash safe, file can be inconsistent after crash between Sync calls.
+// After any I/O error the tree must not be used anymore, all methods return
+// the same error.
+type DiskTree[T constraints.Ordered] struct {
+	f       *os.File
+	codec   Codec[T]
+	recSize int
+	perPage int
+
+	root  uint64
+	count uint64
+	next  uint64 // next never used node
+	free  uint64 // list of deleted nodes linked by left
+
+	pages    map[uint64]*list.Element
+	lru      *list.List
+	maxPages int
+	buf      []byte
+	err      error
+}
+
+type diskPage struct {
+	no    uint64
+	data  []byte
+	dirty bool
+}
+
+// Open opens tree stored in file path or creates new one. Values are
+// encoded with codec, tree must be opened with codec of the same size.
+// cachePages limits number of pages in memory, DefaultCachePages is
+// used if cachePages is not positive.
+func Open[T constraints.Ordered](path string, codec Codec[T], cachePages int) (*DiskTree[T], error) {
+	if cachePages <= 0 {
+		cachePages = DefaultCachePages
+	}
+
+	t := &DiskTree[T]{
+		codec:    codec,
+		recSize:  recValue + codec.Size(),
+		pages:    map[uint64]*list.Element{},
+		lru:      list.New(),
+		maxPages: cachePages,
+		buf:      make([]byte, codec.Size()),
+		next:     1, // node 0 is nil
+	}
+
+	t.perPage = PageSize / t.recSize
+	if t.perPage == 0 {
+		return nil, fmt.Errorf("value of size %d does not fit page", codec.Size())
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	t.f = f
+
+	err = t.readHeader()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Close writes all changes to the file and closes it.
+func (t *DiskTree[T]) Close() error {
+	if t.f == nil {
+		return ErrClosed
+	}
+
+	err := t.Sync()
+	cerr := t.f.Close()
+	t.f = nil
+
+	if err != nil {
+		return err
+	}
+
+	if t.err == nil {
+		t.err = ErrClosed
+	}
+
+	return cerr
+}
+
+// Sync writes all changed pages to the file and commits it to stable storage.
+func (t *DiskTree[T]) Sync() error {
+	if t.err != nil {
+		return t.err
+	}
+
+	for e := t.lru.Front(); e != nil; e = e.Next() {
+		t.writePage(e.Value.(*diskPage))
+	}
+
+	t.writeHeader()
+
+	if t.err == nil {
+		t.fail(t.f.Sync())
+	}
+
+	return t.err
+}
+
+// Len returns number of values in t.
+func (t *DiskTree[T]) Len() int {
+	return int(t.count)
+}
+
+// Insert inserts v to t. Value that codec cannot encode is rejected
+// with error of its Check method, see Codec.
+func (t *DiskTree[T]) Insert(v T) error {
+	if t.err != nil {
+		return t.err
+	}
+
+	err := checkValue(t.codec, v)
+	if err != nil {
+		return err
+	}
+
+	var p uint64
+	n := t.root
+	for n != 0 {
+		p = n
+		if compare.Ordered(v, t.value(n)) > 0 {
+			n = t.right(n)
+		} else {
+			n = t.left(n)
+		}
+	}
+
+	nn := t.alloc()
+	t.setValue(nn, v)
+	t.setParent(nn, p)
+
+	if p == 0 {
+		t.root = nn
+	} else {
+		t.setRed(nn, true)
+
+		if compare.Ordered(v, t.value(p)) > 0 {
+			t.setRight(p, nn)
+		} else {
+			t.setLeft(p, nn)
+		}
+
+		t.insertFixup(nn)
+	}
+
+	t.count++
+	return t.err
+}
+
+// Delete deletes v from t and returns true if v was found.
+func (t *DiskTree[T]) Delete(v T) (bool, error) {
+	if t.err != nil {
+		return false, t.err
+	}
+
+	n := t.find(v)
+	if n == 0 {
+		return false, t.err
+	}
+
+	t.delete(n)
+	t.count--
+
+	return true, t.err
+}
+
+// Contains returns true if t contains value v.
+func (t *DiskTree[T]) Contains(v T) (bool, error) {
+	if t.err != nil {
+		return false, t.err
+	}
+
+	return t.find(v) != 0, t.err
+}
+
+// Min returns min value in t or false if t is empty.
+func (t *DiskTree[T]) Min() (T, bool, error) {
+	var zero T
+	if t.err != nil || t.root == 0 {
+		return zero, false, t.err
+	}
+
+	return t.value(t.min(t.root)), true, t.err
+}
+
+// Max returns max value in t or false if t is empty.
+func (t *DiskTree[T]) Max() (T, bool, error) {
+	var zero T
+	if t.err != nil || t.root == 0 {
+		return zero, false, t.err
+	}
+
+	n := t.root
+	for t.right(n) != 0 {
+		n = t.right(n)
+	}
+
+	return t.value(n), true, t.err
+}
+
+// Ascend calls fn for values of t in ascending order until fn returns false.
+func (t *DiskTree[T]) Ascend(fn func(v T) bool) error {
+	if t.err != nil || t.root == 0 {
+		return t.err
+	}
+
+	for n := t.min(t.root); n != 0 && t.err == nil; n = t.successor(n) {
+		if !fn(t.value(n)) {
+			break
+		}
+	}
+
+	return t.err
+}
+
+func (t *DiskTree[T]) find(v T) uint64 {
+	n := t.root
+	for n != 0 && t.err == nil {
+		c := compare.Ordered(v, t.value(n))
+		if c == 0 {
+			return n
+		} else if c > 0 {
+			n = t.right(n)
+		} else {
+			n = t.left(n)
+		}
+	}
+
+	return 0
+}
+
+func (t *DiskTree[T]) min(n uint64) uint64 {
+	for t.left(n) != 0 {
+		n = t.left(n)
+	}
+
+	return n
+}
+
+func (t *DiskTree[T]) successor(n uint64) uint64 {
+	if r := t.right(n); r != 0 {
+		return t.min(r)
+	}
+
+	p := t.parent(n)
+	for p != 0 && n == t.right(p) {
+		n = p
+		p = t.parent(p)
+	}
+
+	return p
+}
+
+// delete deletes node n and restores red-black properties, it is the same
+// algorithm as Node.delete but node 0 is used instead of fake node. Record
+// 0 is never allocated, it is sentinel of nil node: it is black, its
+// children are never read and its parent is set only while delete is in
+// progress, so deleteFixup can go up from nil child of deleted node.
+func (t *DiskTree[T]) delete(n uint64) {
+	d := n // node that will be physically deleted
+	if t.left(n) != 0 && t.right(n) != 0 {
+		d = t.successor(n)
+	}
+
+	c := t.left(d) // child node that will replace deleted
+	if c == 0 {
+		c = t.right(d)
+	}
+
+	dp := t.parent(d)
+	t.setParent(c, dp)
+
+	if dp == 0 {
+		t.root = c
+	} else if t.left(dp) == d {
+		t.setLeft(dp, c)
+	} else {
+		t.setRight(dp, c)
+	}
+
+	if d != n {
+		t.setValue(n, t.value(d))
+	}
+
+	if !t.red(d) {
+		t.deleteFixup(c)
+	}
+
+	// sentinel is not a child of any node again
+	t.setParent(0, 0)
+	t.release(d)
+}
+
+func (t *DiskTree[T]) deleteFixup(n uint64) {
+	for n != t.root && !t.red(n) && t.err == nil {
+		p := t.parent(n)
+		if n == t.left(p) {
+			// case 1 - transform it to case 2, 3 or 4
+			r := t.right(p)
+			if t.red(r) {
+				t.setRed(r, false)
+				t.setRed(p, true)
+				t.rotateLeft(p)
+				r = t.right(p)
+			}
+
+			if !t.red(t.right(r)) && !t.red(t.left(r)) {
+				// case 2: turn r to red and repeat fixup for n parent
+				t.setRed(r, true)
+				n = p
+			} else {
+				if !t.red(t.right(r)) {
+					// case 3: transform it to case 4
+					t.setRed(t.left(r), false)
+					t.setRed(r, true)
+					t.rotateRight(r)
+					r = t.right(p)
+				}
+
+				// case 4: final case
+				t.setRed(r, t.red(p))
+				t.setRed(p, false)
+				t.setRed(t.right(r), false)
+				t.rotateLeft(p)
+				n = t.root
+			}
+		} else {
+			l := t.left(p)
+			if t.red(l) {
+				t.setRed(l, false)
+				t.setRed(p, true)
+				t.rotateRight(p)
+				l = t.left(p)
+			}
+
+			if !t.red(t.left(l)) && !t.red(t.right(l)) {
+				t.setRed(l, true)
+				n = p
+			} else {
+				if !t.red(t.left(l)) {
+					t.setRed(t.right(l), false)
+					t.setRed(l, true)
+					t.rotateLeft(l)
+					l = t.left(p)
+				}
+
+				t.setRed(l, t.red(p))
+				t.setRed(p, false)
+				t.setRed(t.left(l), false)
+				t.rotateRight(p)
+				n = t.root
+			}
+		}
+	}
+
+	t.setRed(n, false)
+}
+
+// insertFixup restores red-black properties that could be broken after inserting red node n.
+func (t *DiskTree[T]) insertFixup(n uint64) {
+	for t.red(t.parent(n)) && t.err == nil {
+		p := t.parent(n)
+		g := t.parent(p)
+		parentLeft := t.left(g) == p
+
+		uncle := t.left(g)
+		if parentLeft {
+			uncle = t.right(g)
+		}
+
+		if t.red(uncle) {
+			// case 1: we got red uncle
+			t.setRed(uncle, false)
+			t.setRed(p, false)
+			t.setRed(g, true)
+			n = g
+			continue
+		}
+
+		if parentLeft {
+			if t.right(p) == n {
+				// case 2: n is right child
+				n = p
+				t.rotateLeft(n)
+			}
+
+			// case 3: rotate to right
+			p = t.parent(n)
+			t.setRed(p, false)
+			t.setRed(t.parent(p), true)
+			t.rotateRight(t.parent(p))
+		} else {
+			if t.left(p) == n {
+				n = p
+				t.rotateRight(n)
+			}
+
+			p = t.parent(n)
+			t.setRed(p, false)
+			t.setRed(t.parent(p), true)
+			t.rotateLeft(t.parent(p))
+		}
+	}
+
+	t.setRed(t.root, false)
+}
+
+// rotateLeft makes left rotation for node n, see Node.RotateLeft.
+func (t *DiskTree[T]) rotateLeft(n uint64) {
+	c := t.right(n)
+	d := t.left(c)
+
+	t.replaceChild(t.parent(n), n, c)
+	t.setLeft(c, n)
+	t.setRight(n, d)
+}
+
+// rotateRight makes right rotation for node n, see Node.RotateRight.
+func (t *DiskTree[T]) rotateRight(n uint64) {
+	b := t.left(n)
+	e := t.right(b)
+
+	t.replaceChild(t.parent(n), n, b)
+	t.setRight(b, n)
+	t.setLeft(n, e)
+}
+
+func (t *DiskTree[T]) replaceChild(p, old, new uint64) {
+	if p == 0 {
+		t.root = new
+	} else if t.left(p) == old {
+		t.writeUint(p, recLeft, new)
+	} else {
+		t.writeUint(p, recRight, new)
+	}
+
+	if new != 0 {
+		t.writeUint(new, recParent, p)
+	}
+}
+
+func (t *DiskTree[T]) alloc() uint64 {
+	n := t.free
+	if n != 0 {
+		t.free = t.left(n)
+	} else {
+		n = t.next
+		t.next++
+	}
+
+	rec := t.record(n, true)
+	clearValues(rec)
+
+	return n
+}
+
+func (t *DiskTree[T]) release(n uint64) {
+	rec := t.record(n, true)
+	clearValues(rec)
+	binary.LittleEndian.PutUint64(rec[recLeft:], t.free)
+	t.free = n
+}
+
+func (t *DiskTree[T]) left(n uint64) uint64 {
+	return t.readUint(n, recLeft)
+}
+
+func (t *DiskTree[T]) right(n uint64) uint64 {
+	return t.readUint(n, recRight)
+}
+
+func (t *DiskTree[T]) parent(n uint64) uint64 {
+	return t.readUint(n, recParent)
+}
+
+// setLeft sets l as left child for n.
+func (t *DiskTree[T]) setLeft(n, l uint64) {
+	t.writeUint(n, recLeft, l)
+	if l != 0 {
+		t.writeUint(l, recParent, n)
+	}
+}
+
+// setRight sets r as right child for n.
+func (t *DiskTree[T]) setRight(n, r uint64) {
+	t.writeUint(n, recRight, r)
+	if r != 0 {
+		t.writeUint(r, recParent, n)
+	}
+}
+
+// setParent sets parent of n, parent of nil node 0 can be set
+// while delete is in progress.
+func (t *DiskTree[T]) setParent(n, p uint64) {
+	t.writeUint(n, recParent, p)
+}
+
+// red returns true if n is red, nil node is black.
+func (t *DiskTree[T]) red(n uint64) bool {
+	return n != 0 && t.record(n, false)[recFlags] == 1
+}
+
+func (t *DiskTree[T]) setRed(n uint64, red bool) {
+	if n == 0 {
+		return
+	}
+
+	rec := t.record(n, true)
+	rec[recFlags] = 0
+	if red {
+		rec[recFlags] = 1
+	}
+}
+
+func (t *DiskTree[T]) value(n uint64) T {
+	return t.codec.Decode(t.record(n, false)[recValue:])
+}
+
+func (t *DiskTree[T]) setValue(n uint64, v T) {
+	t.codec.Encode(t.buf, v)
+	copy(t.record(n, true)[recValue:], t.buf)
+}
+
+func (t *DiskTree[T]) readUint(n uint64, off int) uint64 {
+	return binary.LittleEndian.Uint64(t.record(n, false)[off:])
+}
+
+func (t *DiskTree[T]) writeUint(n uint64, off int, v uint64) {
+	binary.LittleEndian.PutUint64(t.record(n, true)[off:], v)
+}
+
+// record returns bytes of node n in cached page. Returned slice
+// is valid only until next call of record.
+func (t *DiskTree[T]) record(n uint64, write bool) []byte {
+	p := t.page(1 + n/uint64(t.perPage))
+	if write {
+		p.dirty = true
+	}
+
+	off := int(n%uint64(t.perPage)) * t.recSize
+	return p.data[off : off+t.recSize]
+}
+
+// page returns cached page no, reading it from file if needed.
+// Least recently used page is evicted when cache is full.
+func (t *DiskTree[T]) page(no uint64) *diskPage {
+	if e, ok := t.pages[no]; ok {
+		t.lru.MoveToFront(e)
+		return e.Value.(*diskPage)
+	}
+
+	var p *diskPage
+	if t.lru.Len() >= t.maxPages {
+		e := t.lru.Back()
+		p = e.Value.(*diskPage)
+		t.writePage(p)
+		t.lru.Remove(e)
+		delete(t.pages, p.no)
+	} else {
+		p = &diskPage{
+			data: make([]byte, PageSize),
+		}
+	}
+
+	p.no = no
+	p.dirty = false
+
+	n, err := t.f.ReadAt(p.data, int64(no)*PageSize)
+	if err == io.EOF {
+		// page was never written
+		clearValues(p.data[n:])
+		err = nil
+	}
+
+	t.fail(err)
+	t.pages[no] = t.lru.PushFront(p)
+
+	return p
+}
+
+func (t *DiskTree[T]) writePage(p *diskPage) {
+	if !p.dirty || t.err != nil {
+		return
+	}
+
+	_, err := t.f.WriteAt(p.data, int64(p.no)*PageSize)
+	t.fail(err)
+	p.dirty = false
+}
+
+func (t *DiskTree[T]) readHeader() error {
+	fi, err := t.f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if fi.Size() == 0 {
+		// new file
+		t.writeHeader()
+		return t.err
+	}
+
+	h := make([]byte, PageSize)
+
+	_, err = t.f.ReadAt(h, 0)
+	if err == io.EOF || err == nil && string(h[:len(diskMagic)]) != diskMagic {
+		return errors.New("file is not a disk tree")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if s := binary.LittleEndian.Uint64(h[hdrValueSize:]); s != uint64(t.codec.Size()) {
+		return fmt.Errorf("value size %d does not match codec size %d", s, t.codec.Size())
+	}
+
+	t.root = binary.LittleEndian.Uint64(h[hdrRoot:])
+	t.count = binary.LittleEndian.Uint64(h[hdrCount:])
+	t.next = binary.LittleEndian.Uint64(h[hdrNext:])
+	t.free = binary.LittleEndian.Uint64(h[hdrFree:])
+
+	return nil
+}
+
+func (t *DiskTree[T]) writeHeader() {
+	if t.err != nil {
+		return
+	}
+
+	h := make([]byte, PageSize)
+	copy(h, diskMagic)
+	binary.LittleEndian.PutUint64(h[hdrValueSize:], uint64(t.codec.Size()))
+	binary.LittleEndian.PutUint64(h[hdrRoot:], t.root)
+	binary.LittleEndian.PutUint64(h[hdrCount:], t.count)
+	binary.LittleEndian.PutUint64(h[hdrNext:], t.next)
+	binary.LittleEndian.PutUint64(h[hdrFree:], t.free)
+
+	_, err := t.f.WriteAt(h, 0)
+	t.fail(err)
+}
+
+// fail remembers first I/O error.
+func (t *DiskTree[T]) fail(err error) {
+	if err != nil && t.err == nil {
+		t.err = err
+	}
+}
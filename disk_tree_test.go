@@ -0,0 +1,285 @@
+package rbt_test
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gotest.com/rbt"
+)
+
+func TestDiskTree(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.db")
+
+	// small cache forces pages to be evicted and read back
+	tree, err := rbt.Open[int64](path, rbt.Int64Codec{}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mem := &rbt.Tree[int64]{}
+
+	for i := 0; i < 3000; i++ {
+		v := int64(rand.Intn(1000))
+		if i%3 == 2 {
+			ok, err := tree.Delete(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if ok != mem.Delete(v) {
+				t.Fatal("unexpected delete result", v, ok)
+			}
+		} else {
+			err = tree.Insert(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mem.Insert(v)
+		}
+	}
+
+	expected := treeValues(mem)
+	checkDiskTree(t, tree, expected)
+
+	err = tree.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = tree.Insert(1); err != rbt.ErrClosed {
+		t.Fatal("insert into closed tree", err)
+	}
+
+	tree, err = rbt.Open[int64](path, rbt.Int64Codec{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer tree.Close()
+
+	checkDiskTree(t, tree, expected)
+
+	for _, v := range expected {
+		ok, err := tree.Delete(v)
+		if err != nil || !ok {
+			t.Fatal("value is not deleted", v, err)
+		}
+	}
+
+	checkDiskTree(t, tree, []int64{})
+}
+
+func TestDiskTreeCodecMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.db")
+
+	tree, err := rbt.Open[string](path, rbt.StringCodec{Len: 16}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = tree.Insert("value")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = tree.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = rbt.Open[string](path, rbt.StringCodec{Len: 32}, 0)
+	if err == nil {
+		t.Fatal("tree is opened with wrong codec")
+	}
+
+	tree, err = rbt.Open[string](path, rbt.StringCodec{Len: 16}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer tree.Close()
+
+	if ok, _ := tree.Contains("value"); !ok {
+		t.Fatal("value is lost")
+	}
+}
+
+func TestDiskTreeNotDiskTree(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+
+	text := []byte("not a tree, keep it")
+	err := os.WriteFile(path, text, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = rbt.Open[int64](path, rbt.Int64Codec{}, 0)
+	if err == nil {
+		t.Fatal("text file is opened as disk tree")
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil || !reflect.DeepEqual(b, text) {
+		t.Fatal("text file is changed", string(b), err)
+	}
+}
+
+func TestDiskTreeValueTooLong(t *testing.T) {
+	tree, err := rbt.Open[string](filepath.Join(t.TempDir(), "tree.db"), rbt.StringCodec{Len: 4}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer tree.Close()
+
+	if err = tree.Insert("long value"); err != rbt.ErrValueTooLong {
+		t.Fatal("long value is inserted", err)
+	}
+
+	if err = tree.Insert("long"); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := tree.Len(); n != 1 {
+		t.Fatal("unexpected length", n)
+	}
+}
+
+func checkDiskTree(t *testing.T, tree *rbt.DiskTree[int64], expected []int64) {
+	t.Helper()
+
+	vs := []int64{}
+	err := tree.Ascend(func(v int64) bool {
+		vs = append(vs, v)
+		return true
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(vs, expected) || tree.Len() != len(expected) {
+		t.Fatalf("unexpected values %v, expected %v", vs, expected)
+	}
+
+	if len(expected) == 0 {
+		return
+	}
+
+	if v, ok, err := tree.Min(); err != nil || !ok || v != expected[0] {
+		t.Fatal("unexpected min", v, err)
+	}
+
+	if v, ok, err := tree.Max(); err != nil || !ok || v != expected[len(expected)-1] {
+		t.Fatal("unexpected max", v, err)
+	}
+}
+
+// floatCodec encodes float64 values for tests.
+type floatCodec struct{}
+
+func (floatCodec) Size() int {
+	return 8
+}
+
+func (floatCodec) Encode(b []byte, v float64) {
+	binary.LittleEndian.PutUint64(b, math.Float64bits(v))
+}
+
+func (floatCodec) Decode(b []byte) float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(b))
+}
+
+func TestDiskTreeNaN(t *testing.T) {
+	tree, err := rbt.Open[float64](filepath.Join(t.TempDir(), "tree.db"), floatCodec{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer tree.Close()
+
+	nan := math.NaN()
+	for _, v := range []float64{1, nan, -1, nan} {
+		if err = tree.Insert(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if ok, err := tree.Contains(nan); err != nil || !ok {
+		t.Fatal("NaN is not found", err)
+	}
+
+	if v, ok, err := tree.Min(); err != nil || !ok || !math.IsNaN(v) {
+		t.Fatal("unexpected min", v, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if ok, err := tree.Delete(nan); err != nil || !ok {
+			t.Fatal("NaN is not deleted", err)
+		}
+	}
+
+	if ok, _ := tree.Delete(nan); ok || tree.Len() != 2 {
+		t.Fatal("unexpected delete of NaN")
+	}
+}
+
+func TestDiskTreeDeleteFixup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.db")
+
+	tree, err := rbt.Open[int64](path, rbt.Int64Codec{}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vs := []int64{}
+	for i := 0; i < 2000; i++ {
+		v := int64(rand.Intn(500))
+		if err = tree.Insert(v); err != nil {
+			t.Fatal(err)
+		}
+
+		vs = append(vs, v)
+	}
+
+	if err = rbt.CheckDiskTree(tree); err != nil {
+		t.Fatal(err)
+	}
+
+	err = tree.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// deletes run on nodes that are read back from the file
+	tree, err = rbt.Open[int64](path, rbt.Int64Codec{}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer tree.Close()
+
+	rand.Shuffle(len(vs), func(i, j int) {
+		vs[i], vs[j] = vs[j], vs[i]
+	})
+
+	for i, v := range vs {
+		if ok, err := tree.Delete(v); err != nil || !ok {
+			t.Fatal("value is not deleted", v, err)
+		}
+
+		if i%20 == 0 || len(vs)-i < 20 {
+			if err = rbt.CheckDiskTree(tree); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	checkDiskTree(t, tree, []int64{})
+}
@@ -0,0 +1,182 @@
+package rbt
+
+import (
+	"constraints"
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"reflect"
+
+	"gotest.com/rbt/compare"
+)
+
+const (
+	fnvOffset = 14695981039346656037
+	fnvPrime  = 1099511628211
+)
+
+// Equal reports whether trees a and b hold the same values.
+// Shape and colors of trees are not compared.
+func Equal[T constraints.Ordered](a, b *Tree[T]) bool {
+	if a.Root == b.Root {
+		return true
+	}
+
+	var ia, ib iter[T]
+	x, y := ia.min(a.Root), ib.min(b.Root)
+	for x != nil && y != nil {
+		if compare.Ordered(x.Value, y.Value) != 0 {
+			return false
+		}
+
+		x, y = ia.next(), ib.next()
+	}
+
+	return x == nil && y == nil
+}
+
+// Compare compares values of trees a and b lexicographically.
+// Result is 0 if a == b, -1 if a < b and +1 if a > b.
+func Compare[T constraints.Ordered](a, b *Tree[T]) int {
+	if a.Root == b.Root {
+		return 0
+	}
+
+	var ia, ib iter[T]
+	x, y := ia.min(a.Root), ib.min(b.Root)
+	for x != nil && y != nil {
+		if c := compare.Ordered(x.Value, y.Value); c != 0 {
+			return c
+		}
+
+		x, y = ia.next(), ib.next()
+	}
+
+	if x != nil {
+		return 1
+	} else if y != nil {
+		return -1
+	}
+
+	return 0
+}
+
+// Hash returns hash of values in tree t. Trees that are Equal have
+// the same hash regardless of their shape.
+func Hash[T constraints.Ordered](t *Tree[T]) uint64 {
+	h := fnv.New64a()
+	buf := make([]byte, 8)
+
+	var it iter[T]
+	for n := it.min(t.Root); n != nil; n = it.next() {
+		v := reflect.ValueOf(n.Value)
+
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			binary.LittleEndian.PutUint64(buf, uint64(v.Int()))
+			h.Write(buf)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			binary.LittleEndian.PutUint64(buf, v.Uint())
+			h.Write(buf)
+		case reflect.Float32, reflect.Float64:
+			f := v.Float()
+			if f == 0 {
+				// -0 and +0 are equal, so they must have same hash
+				f = 0
+			} else if f != f {
+				// all NaNs are equal in tree order
+				f = math.NaN()
+			}
+
+			binary.LittleEndian.PutUint64(buf, math.Float64bits(f))
+			h.Write(buf)
+		case reflect.String:
+			s := v.String()
+			binary.LittleEndian.PutUint64(buf, uint64(len(s)))
+			h.Write(buf)
+			h.Write([]byte(s))
+		}
+	}
+
+	return h.Sum64()
+}
+
+// StructurallyEqual reports whether trees a and b have the same values,
+// colors and shape. It is useful in tests to compare trees exactly.
+func StructurallyEqual[T constraints.Ordered](a, b *Tree[T]) bool {
+	return a.Root.structurallyEqual(b.Root)
+}
+
+func (n *Node[T]) structurallyEqual(o *Node[T]) bool {
+	if n == nil || o == nil {
+		return n == o
+	}
+
+	return compare.Ordered(n.Value, o.Value) == 0 && n.Red == o.Red &&
+		n.Left.structurallyEqual(o.Left) &&
+		n.Right.structurallyEqual(o.Right)
+}
+
+// EqualCmp reports whether trees a and b hold the same values according
+// to a.Cmp. Shape and colors of trees are not compared.
+func EqualCmp[T any](a, b *TreeCmp[T]) bool {
+	return CompareCmp(a, b) == 0
+}
+
+// CompareCmp compares values of trees a and b lexicographically using a.Cmp.
+// Result is 0 if a == b, -1 if a < b and +1 if a > b.
+func CompareCmp[T any](a, b *TreeCmp[T]) int {
+	if a.Root == b.Root {
+		return 0
+	}
+
+	var ia, ib iterCmp[T]
+	x, y := ia.min(a.Root), ib.min(b.Root)
+	for x != nil && y != nil {
+		c := a.Cmp(x.Value, y.Value)
+		if c < 0 {
+			return -1
+		} else if c > 0 {
+			return 1
+		}
+
+		x, y = ia.next(), ib.next()
+	}
+
+	if x != nil {
+		return 1
+	} else if y != nil {
+		return -1
+	}
+
+	return 0
+}
+
+// HashCmp returns hash of values in tree t combining hashes returned by h
+// in tree order. h must return same hash for values that are equal
+// according to t.Cmp.
+func HashCmp[T any](t *TreeCmp[T], h func(v T) uint64) uint64 {
+	s := uint64(fnvOffset)
+	var it iterCmp[T]
+	for n := it.min(t.Root); n != nil; n = it.next() {
+		s = (s ^ h(n.Value)) * fnvPrime
+	}
+
+	return s
+}
+
+// StructurallyEqualCmp reports whether trees a and b have the same values,
+// colors and shape. Values are compared with a.Cmp.
+func StructurallyEqualCmp[T any](a, b *TreeCmp[T]) bool {
+	return a.Root.structurallyEqual(b.Root, a.Cmp)
+}
+
+func (n *NodeCmp[T]) structurallyEqual(o *NodeCmp[T], cmp func(a, b T) int) bool {
+	if n == nil || o == nil {
+		return n == o
+	}
+
+	return cmp(n.Value, o.Value) == 0 && n.Red == o.Red &&
+		n.Left.structurallyEqual(o.Left, cmp) &&
+		n.Right.structurallyEqual(o.Right, cmp)
+}
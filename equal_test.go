@@ -0,0 +1,120 @@
+package rbt_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gotest.com/rbt"
+	"gotest.com/rbt/compare"
+)
+
+func TestEqual(t *testing.T) {
+	a := &rbt.Tree[int]{}
+	b := &rbt.Tree[int]{}
+
+	for i := 1; i <= 4; i++ {
+		a.Insert(i)
+		b.Insert(5 - i)
+	}
+
+	if !rbt.Equal(a, b) {
+		t.Fatal("trees with same values are not equal")
+	}
+
+	if rbt.Compare(a, b) != 0 {
+		t.Fatal("trees with same values compare not equal")
+	}
+
+	if rbt.Hash(a) != rbt.Hash(b) {
+		t.Fatal("trees with same values have different hash")
+	}
+
+	if rbt.StructurallyEqual(a, b) {
+		t.Fatal("trees with different shape are structurally equal")
+	}
+
+	if !rbt.StructurallyEqual(a, a.Clone()) {
+		t.Fatal("clone is not structurally equal")
+	}
+
+	b.Insert(5)
+
+	if rbt.Equal(a, b) {
+		t.Fatal("trees with different values are equal")
+	}
+
+	if rbt.Compare(a, b) != -1 || rbt.Compare(b, a) != 1 {
+		t.Fatal("prefix must be less")
+	}
+
+	if rbt.Hash(a) == rbt.Hash(b) {
+		t.Fatal("trees with different values have same hash")
+	}
+
+	a.Insert(6)
+
+	if rbt.Compare(a, b) != 1 || rbt.Compare(b, a) != -1 {
+		t.Fatal("wrong lexicographic order")
+	}
+}
+
+func TestHashShapeIndependent(t *testing.T) {
+	a := &rbt.Tree[string]{}
+	b := &rbt.Tree[string]{}
+
+	vs := []string{"a", "b", "c", "ab", "ba", "", "abc", "cab", "bca"}
+	for _, v := range vs {
+		a.Insert(v)
+	}
+
+	for _, i := range rand.Perm(len(vs)) {
+		b.Insert(vs[i])
+	}
+
+	if !rbt.Equal(a, b) || rbt.Hash(a) != rbt.Hash(b) {
+		t.Fatal("hash depends on insertion order")
+	}
+
+	f := &rbt.Tree[float64]{}
+	g := &rbt.Tree[float64]{}
+	f.Insert(0)
+	g.Insert(math.Copysign(0, -1))
+
+	if !rbt.Equal(f, g) || rbt.Hash(f) != rbt.Hash(g) {
+		t.Fatal("hash differs for -0 and +0")
+	}
+}
+
+func TestEqualCmp(t *testing.T) {
+	cmp := compare.Ordered[int]
+	a := &rbt.TreeCmp[int]{Cmp: cmp}
+	b := &rbt.TreeCmp[int]{Cmp: cmp}
+
+	for i := 0; i < 10; i++ {
+		a.Insert(i)
+		b.Insert(9 - i)
+	}
+
+	h := func(v int) uint64 {
+		return uint64(v)
+	}
+
+	if !rbt.EqualCmp(a, b) || rbt.HashCmp(a, h) != rbt.HashCmp(b, h) {
+		t.Fatal("trees with same values are not equal")
+	}
+
+	if !rbt.StructurallyEqualCmp(a, a.Clone()) {
+		t.Fatal("clone is not structurally equal")
+	}
+
+	b.Delete(9)
+
+	if rbt.EqualCmp(a, b) || rbt.CompareCmp(a, b) != 1 {
+		t.Fatal("trees with different values are equal")
+	}
+
+	if rbt.HashCmp(a, h) == rbt.HashCmp(b, h) {
+		t.Fatal("trees with different values have same hash")
+	}
+}
@@ -0,0 +1,239 @@
+package rbt
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultReapInterval is interval between evictions of Expiring reaper
+// that is started with zero interval.
+const DefaultReapInterval = time.Second
+
+// Clock provides time to Expiring, it can be replaced in tests.
+type Clock interface {
+	Now() time.Time
+	// After returns channel that receives current time after d.
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Expiring represents set of items with time to live. Items are kept in
+// TreeCmp ordered by deadline, so expired items are evicted from its min
+// in O(log n) each. Expired items are evicted by ExpireUntil or by reaper
+// goroutine that is started with Start. Zero Expiring is ready to use,
+// it is safe for concurrent use.
+type Expiring[T comparable] struct {
+	// OnExpire is called for every evicted item without lock held,
+	// so it can change the set. It must not be changed after first use.
+	OnExpire func(item T, deadline time.Time)
+	// Clock is source of time, nil means system clock.
+	// It must not be changed after first use.
+	Clock Clock
+
+	mu      sync.Mutex
+	tree    TreeCmp[expiringEntry[T]]
+	entries map[T]expiringEntry[T]
+	seq     uint64 // makes entries with equal deadlines unique
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+type expiringEntry[T comparable] struct {
+	deadline time.Time
+	seq      uint64
+	item     T
+}
+
+func cmpExpiringEntry[T comparable](a, b expiringEntry[T]) int {
+	if a.deadline.Before(b.deadline) {
+		return -1
+	} else if a.deadline.After(b.deadline) {
+		return 1
+	} else if a.seq < b.seq {
+		return -1
+	} else if a.seq > b.seq {
+		return 1
+	}
+
+	return 0
+}
+
+// Add adds item that expires after ttl. Deadline of item that is already
+// in e is replaced.
+func (e *Expiring[T]) Add(item T, ttl time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.init()
+	e.remove(item)
+	e.add(item, e.clock().Now().Add(ttl))
+}
+
+// Touch sets deadline of item to ttl from now and returns false if item is not in e.
+func (e *Expiring[T]) Touch(item T, ttl time.Duration) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.init()
+	if !e.remove(item) {
+		return false
+	}
+
+	e.add(item, e.clock().Now().Add(ttl))
+
+	return true
+}
+
+// Remove removes item without calling OnExpire and returns false if item is not in e.
+func (e *Expiring[T]) Remove(item T) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.init()
+
+	return e.remove(item)
+}
+
+// Deadline returns deadline of item or false if item is not in e.
+func (e *Expiring[T]) Deadline(item T) (time.Time, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	en, ok := e.entries[item]
+
+	return en.deadline, ok
+}
+
+// Len returns number of items in e, including expired ones that are not evicted yet.
+func (e *Expiring[T]) Len() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.tree.Len()
+}
+
+// ExpireUntil evicts items with deadline not after now in order of deadlines,
+// calls OnExpire for them and returns number of evicted items.
+func (e *Expiring[T]) ExpireUntil(now time.Time) int {
+	e.mu.Lock()
+	e.init()
+
+	expired := []expiringEntry[T]{}
+	for {
+		en, ok := e.tree.PeekMin()
+		if !ok || en.deadline.After(now) {
+			break
+		}
+
+		e.tree.PopMin()
+		delete(e.entries, en.item)
+		expired = append(expired, en)
+	}
+	e.mu.Unlock()
+
+	if e.OnExpire != nil {
+		for _, en := range expired {
+			e.OnExpire(en.item, en.deadline)
+		}
+	}
+
+	return len(expired)
+}
+
+// Start starts reaper goroutine that evicts expired items every interval,
+// zero interval means DefaultReapInterval. Start does nothing if reaper
+// is already running.
+func (e *Expiring[T]) Start(interval time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.stop != nil {
+		return
+	}
+
+	if interval == 0 {
+		interval = DefaultReapInterval
+	}
+
+	e.stop = make(chan struct{})
+	e.done = make(chan struct{})
+
+	go e.reap(interval, e.stop, e.done)
+}
+
+// Stop stops reaper goroutine and waits until it exits.
+func (e *Expiring[T]) Stop() {
+	e.mu.Lock()
+	stop, done := e.stop, e.done
+	e.stop, e.done = nil, nil
+	e.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+
+	close(stop)
+	<-done
+}
+
+func (e *Expiring[T]) reap(interval time.Duration, stop, done chan struct{}) {
+	defer close(done)
+
+	c := e.clock()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-c.After(interval):
+			e.ExpireUntil(c.Now())
+		}
+	}
+}
+
+func (e *Expiring[T]) init() {
+	if e.entries == nil {
+		e.entries = map[T]expiringEntry[T]{}
+		e.tree.Cmp = cmpExpiringEntry[T]
+	}
+}
+
+func (e *Expiring[T]) clock() Clock {
+	if e.Clock == nil {
+		return systemClock{}
+	}
+
+	return e.Clock
+}
+
+func (e *Expiring[T]) add(item T, deadline time.Time) {
+	e.seq++
+	en := expiringEntry[T]{
+		deadline: deadline,
+		seq:      e.seq,
+		item:     item,
+	}
+
+	e.entries[item] = en
+	e.tree.Insert(en)
+}
+
+func (e *Expiring[T]) remove(item T) bool {
+	en, ok := e.entries[item]
+	if !ok {
+		return false
+	}
+
+	delete(e.entries, item)
+	e.tree.Delete(en)
+
+	return true
+}
@@ -0,0 +1,130 @@
+package rbt_test
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.com/rbt"
+)
+
+// fakeClock is Clock that is moved forward by tests.
+type fakeClock struct {
+	mu   sync.Mutex
+	now  time.Time
+	tick chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{
+		now:  time.Unix(1000, 0),
+		tick: make(chan time.Time),
+	}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	return c.tick
+}
+
+// Advance moves c forward by d and fires timer of waiting reaper.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	now := c.now
+	c.mu.Unlock()
+
+	c.tick <- now
+}
+
+func TestExpiring(t *testing.T) {
+	clock := newFakeClock()
+	expired := []string{}
+
+	e := &rbt.Expiring[string]{
+		Clock: clock,
+		OnExpire: func(item string, deadline time.Time) {
+			expired = append(expired, item)
+		},
+	}
+
+	e.Add("a", 3*time.Second)
+	e.Add("b", time.Second)
+	e.Add("c", 2*time.Second)
+	e.Add("d", 2*time.Second)
+	e.Add("e", 5*time.Second)
+
+	if !e.Touch("b", 4*time.Second) || e.Touch("x", time.Second) {
+		t.Fatal("unexpected touch")
+	}
+
+	if !e.Remove("c") || e.Remove("c") {
+		t.Fatal("unexpected remove")
+	}
+
+	now := clock.Now()
+	if n := e.ExpireUntil(now.Add(time.Second)); n != 0 {
+		t.Fatal("unexpected evicted items", n)
+	}
+
+	if n := e.ExpireUntil(now.Add(4 * time.Second)); n != 3 {
+		t.Fatal("unexpected evicted items", n)
+	}
+
+	if !reflect.DeepEqual(expired, []string{"d", "a", "b"}) {
+		t.Fatal("unexpected expired items", expired)
+	}
+
+	if d, ok := e.Deadline("e"); !ok || !d.Equal(now.Add(5*time.Second)) || e.Len() != 1 {
+		t.Fatal("unexpected deadline", d, ok)
+	}
+
+	if _, ok := e.Deadline("a"); ok {
+		t.Fatal("expired item has deadline")
+	}
+}
+
+func TestExpiringReaper(t *testing.T) {
+	clock := newFakeClock()
+	expired := make(chan int, 10)
+
+	e := &rbt.Expiring[int]{Clock: clock}
+	e.OnExpire = func(item int, deadline time.Time) {
+		// callback can change the set
+		if item == 1 {
+			e.Add(3, time.Minute)
+		}
+
+		expired <- item
+	}
+
+	e.Add(1, time.Second)
+	e.Add(2, time.Hour)
+
+	e.Start(time.Second)
+	e.Start(time.Second)
+	defer e.Stop()
+
+	clock.Advance(time.Second)
+	if item := <-expired; item != 1 {
+		t.Fatal("unexpected expired item", item)
+	}
+
+	clock.Advance(time.Minute)
+	if item := <-expired; item != 3 {
+		t.Fatal("unexpected expired item", item)
+	}
+
+	e.Stop()
+
+	if e.Len() != 1 || len(expired) != 0 {
+		t.Fatal("unexpected items", e.Len(), len(expired))
+	}
+}
@@ -0,0 +1,80 @@
+package rbt
+
+import (
+	"constraints"
+	"fmt"
+
+	"gotest.com/rbt/compare"
+)
+
+// CheckDiskTree checks that t stored on disk is valid red-black tree with
+// valid parent links and that nil sentinel has no parent.
+func CheckDiskTree[T constraints.Ordered](t *DiskTree[T]) error {
+	if p := t.parent(0); p != 0 {
+		return fmt.Errorf("sentinel has parent %d", p)
+	}
+
+	if t.red(t.root) {
+		return fmt.Errorf("root %d is red", t.root)
+	}
+
+	if t.root != 0 && t.parent(t.root) != 0 {
+		return fmt.Errorf("root %d has parent", t.root)
+	}
+
+	_, err := t.checkNode(t.root)
+	if err == nil {
+		err = t.err
+	}
+
+	return err
+}
+
+// checkNode checks subtree n and returns its black height.
+func (t *DiskTree[T]) checkNode(n uint64) (int, error) {
+	if n == 0 {
+		return 0, nil
+	}
+
+	for _, c := range []uint64{t.left(n), t.right(n)} {
+		if c == 0 {
+			continue
+		}
+
+		if p := t.parent(c); p != n {
+			return 0, fmt.Errorf("wrong parent %d of %d, expected %d", p, c, n)
+		}
+
+		if t.red(n) && t.red(c) {
+			return 0, fmt.Errorf("red node %d has red child %d", n, c)
+		}
+	}
+
+	if l := t.left(n); l != 0 && compare.Ordered(t.value(l), t.value(n)) > 0 {
+		return 0, fmt.Errorf("left child %v is greater than %v", t.value(l), t.value(n))
+	}
+
+	if r := t.right(n); r != 0 && compare.Ordered(t.value(r), t.value(n)) < 0 {
+		return 0, fmt.Errorf("right child %v is less than %v", t.value(r), t.value(n))
+	}
+
+	bl, err := t.checkNode(t.left(n))
+	if err != nil {
+		return 0, err
+	}
+
+	br, err := t.checkNode(t.right(n))
+	if err != nil {
+		return 0, err
+	}
+
+	if bl != br {
+		return 0, fmt.Errorf("black height differs for %v; %d != %d", t.value(n), bl, br)
+	}
+
+	if !t.red(n) {
+		bl++
+	}
+
+	return bl, nil
+}
@@ -0,0 +1,76 @@
+package rbt
+
+import (
+	"gotest.com/rbt/compare"
+)
+
+// InsertHint inserts v starting search from hint instead of root and
+// returns node of v that can be hint for the next insert. Hint must be
+// node of t or nil. If v goes next to hint, like on sequential appends,
+// insert takes amortised O(1) comparisons, otherwise search goes up from
+// hint only as far as needed. Hint that is not in t anymore, because it was
+// deleted or t was rebuilt, is ignored and search starts from root. Sizes and
+// hashes of subtrees, if they are enabled, are updated up to the root.
+func (t *Tree[T]) InsertHint(hint *Node[T], v T) *Node[T] {
+	return t.insertNear(hint, v)
+}
+
+// FindFrom finds node with value v starting search from node n of t
+// instead of root, or from root if n is nil. Search goes up from n only
+// until subtree has place of v, so values close to n are found in
+// amortised O(1).
+func (t *Tree[T]) FindFrom(n *Node[T], v T) *Node[T] {
+	// Parent links are valid only in nodes attached to t
+	if !t.attached(n) {
+		return t.Root.Find(v)
+	}
+
+	c := compare.Ordered(v, n.Value)
+	if c == 0 {
+		return n
+	}
+
+	// values of subtree n are bounded by parents of the first left and
+	// right subtrees, value equal to bound is not in subtree
+	if c > 0 {
+		for n.Parent != nil && !(n == n.Parent.Left && compare.Ordered(v, n.Parent.Value) < 0) {
+			n = n.Parent
+		}
+	} else {
+		for n.Parent != nil && !(n == n.Parent.Right && compare.Ordered(v, n.Parent.Value) > 0) {
+			n = n.Parent
+		}
+	}
+
+	return n.Find(v)
+}
+
+// InsertHint inserts v starting search from hint, see Tree.InsertHint.
+func (t *TreeCmp[T]) InsertHint(hint *NodeCmp[T], v T) *NodeCmp[T] {
+	return t.insertNear(hint, v)
+}
+
+// FindFrom finds node with value v starting search from node n of t, see Tree.FindFrom.
+func (t *TreeCmp[T]) FindFrom(n *NodeCmp[T], v T) *NodeCmp[T] {
+	cmp := t.cmp()
+	if !t.attached(n) {
+		return t.Root.Find(v, cmp)
+	}
+
+	c := cmp(v, n.Value)
+	if c == 0 {
+		return n
+	}
+
+	if c > 0 {
+		for n.Parent != nil && !(n == n.Parent.Left && cmp(v, n.Parent.Value) < 0) {
+			n = n.Parent
+		}
+	} else {
+		for n.Parent != nil && !(n == n.Parent.Right && cmp(v, n.Parent.Value) > 0) {
+			n = n.Parent
+		}
+	}
+
+	return n.Find(v, cmp)
+}
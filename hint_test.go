@@ -0,0 +1,254 @@
+package rbt_test
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"gotest.com/rbt"
+	"gotest.com/rbt/compare"
+)
+
+func TestTreeInsertHint(t *testing.T) {
+	tree := &rbt.Tree[int]{}
+
+	// appends at the right end and local inserts around the last one
+	var hint *rbt.Node[int]
+	vs := []int{}
+	for i := 0; i < 2000; i++ {
+		v := i
+		if i%10 == 0 {
+			v = i - rand.Intn(50)
+		}
+
+		hint = tree.InsertHint(hint, v)
+		if hint.Value != v {
+			t.Fatal("unexpected node", hint.Value, v)
+		}
+
+		vs = append(vs, v)
+	}
+
+	if err := checkTree(tree.Root); err != nil {
+		t.Fatal(err)
+	}
+
+	prev := -1 << 31
+	tree.Ascend(func(v int) bool {
+		if v < prev {
+			t.Fatal("wrong order", prev, v)
+		}
+
+		prev = v
+		return true
+	})
+
+	if max, _ := tree.Max(); max != 1999 || tree.Len() != len(vs) || tree.HeadSet(2000).Len() != len(vs) {
+		t.Fatal("unexpected max or len", max, tree.Len())
+	}
+
+	// hint from far away still gives valid tree
+	tree.InsertHint(tree.Root.Min(), 5000)
+	tree.InsertHint(tree.Root.Max(), -5000)
+
+	if err := checkTree(tree.Root); err != nil {
+		t.Fatal(err)
+	}
+
+	if min, _ := tree.Min(); min != -5000 {
+		t.Fatal("unexpected min", min)
+	}
+
+	if max, _ := tree.Max(); max != 5000 {
+		t.Fatal("unexpected max", max)
+	}
+}
+
+func TestTreeFindFrom(t *testing.T) {
+	tree := &rbt.Tree[int]{}
+	for _, v := range rand.Perm(1000) {
+		tree.Insert(v * 2)
+	}
+
+	nodes := []*rbt.Node[int]{nil}
+	for n := tree.Root.Min(); n != nil; n = n.Successor() {
+		nodes = append(nodes, n)
+	}
+
+	for i := 0; i < 5000; i++ {
+		from := nodes[rand.Intn(len(nodes))]
+		v := rand.Intn(2002) - 1
+
+		n := tree.FindFrom(from, v)
+		if v%2 == 0 && v < 2000 && v >= 0 {
+			if n == nil || n.Value != v {
+				t.Fatal("value is not found", v)
+			}
+		} else if n != nil {
+			t.Fatal("missing value is found", v)
+		}
+	}
+
+	// nodes of shared tree are copied on write, old node is not used
+	c := tree.Clone()
+	c.Insert(1)
+	if n := c.FindFrom(nodes[1], 1); n == nil || n.Value != 1 {
+		t.Fatal("value is not found after copy")
+	}
+}
+
+func TestTreeInsertHintDeleted(t *testing.T) {
+	tree := &rbt.Tree[int]{}
+	mem := map[int]int{}
+
+	hints := []*rbt.Node[int]{}
+	for i := 0; i < 3000; i++ {
+		var hint *rbt.Node[int]
+		if len(hints) > 0 {
+			hint = hints[rand.Intn(len(hints))]
+		}
+
+		// hints can be deleted nodes, successors that were unlinked
+		// by delete of their predecessors, or nodes of rebuilt tree
+		switch v := rand.Intn(500); i % 3 {
+		case 0, 1:
+			hints = append(hints, tree.InsertHint(hint, v))
+			mem[v]++
+		default:
+			if tree.Delete(v) {
+				mem[v]--
+			}
+		}
+
+		if i%1000 == 999 {
+			k := tree.Len()
+			tree.InsertBatch(rand.Perm(k))
+			for v := 0; v < k; v++ {
+				mem[v]++
+			}
+		}
+
+		if n := tree.FindFrom(hint, 250); (n != nil) != (mem[250] > 0) {
+			t.Fatal("unexpected find of 250", n != nil, mem[250])
+		}
+	}
+
+	if err := checkTree(tree.Root); err != nil {
+		t.Fatal(err)
+	}
+
+	for v, c := range mem {
+		for ; c > 0; c-- {
+			if !tree.Delete(v) {
+				t.Fatal("value is lost", v)
+			}
+		}
+	}
+
+	if tree.Len() != 0 || tree.Root != nil {
+		t.Fatal("unexpected values left", tree.Len())
+	}
+}
+
+func TestTreeInsertHintForeign(t *testing.T) {
+	other := &rbt.Tree[int]{}
+	for i := 0; i < 10; i++ {
+		other.Insert(i)
+	}
+
+	// hint of another tree is ignored even by empty tree without token
+	tree := &rbt.Tree[int]{}
+	tree.InsertHint(other.Root.Max(), 20)
+	tree.InsertHint(other.Root.Min(), 30)
+
+	if other.Len() != 10 || other.Contains(20) || other.Contains(30) {
+		t.Fatal("value is inserted into tree of hint")
+	}
+
+	if err := checkTree(tree.Root); err != nil || tree.Len() != 2 || !tree.Contains(20) {
+		t.Fatal("unexpected tree", tree.Len(), err)
+	}
+
+	s := &rbt.Seq[int]{}
+	for i := 0; i < 10; i++ {
+		s.InsertAt(i, i)
+	}
+
+	cmp := &rbt.TreeCmp[int]{Cmp: compare.Ordered[int]}
+	cmp.InsertHint(s.Root.Max(), 20)
+	if s.Len() != 10 || cmp.Len() != 1 || cmp.FindFrom(s.Root, 20) == nil {
+		t.Fatal("hint of sequence is not ignored", s.Len(), cmp.Len())
+	}
+}
+
+func TestTreeCmpHint(t *testing.T) {
+	tree := rbt.NewTreeCmp(compare.Ordered[int])
+
+	var hint *rbt.NodeCmp[int]
+	for i := 0; i < 1000; i++ {
+		hint = tree.InsertHint(hint, i)
+	}
+
+	if err := checkTreeCmp(tree.Root); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 999; i >= 0; i-- {
+		if hint = tree.FindFrom(hint, i); hint == nil || hint.Value != i {
+			t.Fatal("value is not found", i)
+		}
+	}
+
+	if tree.FindFrom(hint, 1000) != nil {
+		t.Fatal("missing value is found")
+	}
+}
+
+func BenchmarkTreeInsertHintAppend(b *testing.B) {
+	tree := &rbt.Tree[int]{}
+
+	var hint *rbt.Node[int]
+	for i := 0; i < b.N; i++ {
+		hint = tree.InsertHint(hint, i)
+	}
+}
+
+func BenchmarkTreeInsertAppend(b *testing.B) {
+	tree := &rbt.Tree[int]{}
+	for i := 0; i < b.N; i++ {
+		tree.Insert(i)
+	}
+}
+
+// appendKeys returns n ascending keys with long common prefix, so
+// comparisons are not cheap.
+func appendKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("/tenants/acme/users/%012d", i)
+	}
+
+	return keys
+}
+
+func BenchmarkTreeCmpInsertHintAppend(b *testing.B) {
+	keys := appendKeys(b.N)
+	tree := rbt.NewTreeCmp(strings.Compare)
+	b.ResetTimer()
+
+	var hint *rbt.NodeCmp[string]
+	for _, k := range keys {
+		hint = tree.InsertHint(hint, k)
+	}
+}
+
+func BenchmarkTreeCmpInsertAppend(b *testing.B) {
+	keys := appendKeys(b.N)
+	tree := rbt.NewTreeCmp(strings.Compare)
+	b.ResetTimer()
+
+	for _, k := range keys {
+		tree.Insert(k)
+	}
+}
@@ -0,0 +1,199 @@
+package rbt
+
+import (
+	"errors"
+)
+
+var (
+	// ErrDuplicate is returned when record violates unique index.
+	ErrDuplicate = errors.New("duplicate record in unique index")
+	// ErrNotFound is returned by Update when there is no record with the same primary key.
+	ErrNotFound = errors.New("record not found")
+)
+
+// Index describes index of IndexedCollection.
+type Index[R any] struct {
+	Name string
+	// Cmp orders records by keys of the index, records are equal in the
+	// index if Cmp returns zero.
+	Cmp func(a, b R) int
+	// Unique forbids records that are equal in the index.
+	Unique bool
+}
+
+// IndexedCollection represents collection of records with several indexes.
+// Every index is TreeCmp of the same records, indexes are kept in sync on
+// every change. The first index is primary, it is always unique and
+// identifies records for Update and Delete. Records of not unique index
+// are ordered by primary index when they are equal.
+type IndexedCollection[R any] struct {
+	indexes []*collectionIndex[R]
+}
+
+type collectionIndex[R any] struct {
+	Index[R]
+	tree TreeCmp[R]
+}
+
+// NewIndexedCollection returns empty collection with indexes, the first
+// of them is primary.
+func NewIndexedCollection[R any](primary Index[R], indexes ...Index[R]) *IndexedCollection[R] {
+	primary.Unique = true
+
+	c := &IndexedCollection[R]{}
+	for _, idx := range append([]Index[R]{primary}, indexes...) {
+		if idx.Cmp == nil {
+			panic(ErrNilCmp)
+		}
+
+		ci := &collectionIndex[R]{Index: idx}
+		ci.tree.Cmp = idx.Cmp
+
+		if !idx.Unique {
+			cmp := idx.Cmp
+			ci.tree.Cmp = func(a, b R) int {
+				if c := cmp(a, b); c != 0 {
+					return c
+				}
+
+				return primary.Cmp(a, b)
+			}
+		}
+
+		c.indexes = append(c.indexes, ci)
+	}
+
+	return c
+}
+
+// Insert inserts r to all indexes. ErrDuplicate is returned if r violates
+// any unique index, then collection is not changed.
+func (c *IndexedCollection[R]) Insert(r R) error {
+	if c.conflicts(r) {
+		return ErrDuplicate
+	}
+
+	for _, idx := range c.indexes {
+		idx.tree.Insert(r)
+	}
+
+	return nil
+}
+
+// Update replaces record with the same primary key as r. ErrNotFound is
+// returned if there is no such record and ErrDuplicate is returned if r
+// violates any unique index, then collection is not changed.
+func (c *IndexedCollection[R]) Update(r R) error {
+	old, ok := c.indexes[0].tree.Find(r)
+	if !ok {
+		return ErrNotFound
+	}
+
+	c.remove(old)
+
+	if c.conflicts(r) {
+		for _, idx := range c.indexes {
+			idx.tree.Insert(old)
+		}
+
+		return ErrDuplicate
+	}
+
+	for _, idx := range c.indexes {
+		idx.tree.Insert(r)
+	}
+
+	return nil
+}
+
+// Delete deletes record with the same primary key as r and returns false
+// if there is no such record.
+func (c *IndexedCollection[R]) Delete(r R) bool {
+	old, ok := c.indexes[0].tree.Find(r)
+	if !ok {
+		return false
+	}
+
+	c.remove(old)
+
+	return true
+}
+
+// Get returns the first record that is equal to key in index or false if
+// there is no such record. Only fields of key that are compared by index
+// have to be set.
+func (c *IndexedCollection[R]) Get(index string, key R) (R, bool) {
+	idx := c.index(index)
+
+	n := idx.tree.Root.LowerBound(key, idx.Cmp)
+	if n == nil || idx.Cmp(n.Value, key) != 0 {
+		var zero R
+		return zero, false
+	}
+
+	return n.Value, true
+}
+
+// GetAll returns all records that are equal to key in index ordered by
+// primary index.
+func (c *IndexedCollection[R]) GetAll(index string, key R) []R {
+	idx := c.index(index)
+
+	var rs []R
+	for n := idx.tree.Root.LowerBound(key, idx.Cmp); n != nil && idx.Cmp(n.Value, key) == 0; n = n.Successor() {
+		rs = append(rs, n.Value)
+	}
+
+	return rs
+}
+
+// Ascend calls fn for records in order of index until fn returns false.
+func (c *IndexedCollection[R]) Ascend(index string, fn func(r R) bool) {
+	c.index(index).tree.Ascend(fn)
+}
+
+// AscendRange calls fn for records from lo (inclusive) to hi (exclusive)
+// in order of index until fn returns false.
+func (c *IndexedCollection[R]) AscendRange(index string, lo, hi R, fn func(r R) bool) {
+	idx := c.index(index)
+
+	for n := idx.tree.Root.LowerBound(lo, idx.Cmp); n != nil && idx.Cmp(n.Value, hi) < 0; n = n.Successor() {
+		if !fn(n.Value) {
+			return
+		}
+	}
+}
+
+// Len returns number of records.
+func (c *IndexedCollection[R]) Len() int {
+	return c.indexes[0].tree.Len()
+}
+
+// index returns index by name, it panics if there is no such index.
+func (c *IndexedCollection[R]) index(name string) *collectionIndex[R] {
+	for _, idx := range c.indexes {
+		if idx.Name == name {
+			return idx
+		}
+	}
+
+	panic("unknown index " + name)
+}
+
+// conflicts returns true if r is equal to any record in unique index.
+func (c *IndexedCollection[R]) conflicts(r R) bool {
+	for _, idx := range c.indexes {
+		if idx.Unique && idx.tree.Contains(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// remove removes r from all indexes, r must be stored record.
+func (c *IndexedCollection[R]) remove(r R) {
+	for _, idx := range c.indexes {
+		idx.tree.Delete(r)
+	}
+}
@@ -0,0 +1,120 @@
+package rbt_test
+
+import (
+	"reflect"
+	"testing"
+
+	"gotest.com/rbt"
+	"gotest.com/rbt/compare"
+)
+
+type record struct {
+	id   int
+	ts   int64
+	name string
+}
+
+func newRecords() *rbt.IndexedCollection[record] {
+	return rbt.NewIndexedCollection(
+		rbt.Index[record]{Name: "id", Cmp: compare.By(func(r record) int { return r.id })},
+		rbt.Index[record]{Name: "ts", Cmp: compare.By(func(r record) int64 { return r.ts })},
+		rbt.Index[record]{Name: "name", Cmp: compare.By(func(r record) string { return r.name }), Unique: true},
+	)
+}
+
+func recordIDs(c *rbt.IndexedCollection[record], index string) []int {
+	ids := []int{}
+	c.Ascend(index, func(r record) bool {
+		ids = append(ids, r.id)
+		return true
+	})
+
+	return ids
+}
+
+func TestIndexedCollection(t *testing.T) {
+	c := newRecords()
+
+	for _, r := range []record{{1, 30, "c"}, {2, 10, "a"}, {3, 30, "b"}, {4, 20, "d"}} {
+		if err := c.Insert(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := c.Insert(record{1, 0, "x"}); err != rbt.ErrDuplicate {
+		t.Fatal("duplicate id is inserted", err)
+	}
+
+	if err := c.Insert(record{5, 0, "a"}); err != rbt.ErrDuplicate {
+		t.Fatal("duplicate name is inserted", err)
+	}
+
+	if c.Len() != 4 {
+		t.Fatal("collection is changed by failed insert", c.Len())
+	}
+
+	if ids := recordIDs(c, "ts"); !reflect.DeepEqual(ids, []int{2, 4, 1, 3}) {
+		t.Fatal("unexpected ts order", ids)
+	}
+
+	if ids := recordIDs(c, "name"); !reflect.DeepEqual(ids, []int{2, 3, 1, 4}) {
+		t.Fatal("unexpected name order", ids)
+	}
+
+	if r, ok := c.Get("name", record{name: "b"}); !ok || r.id != 3 {
+		t.Fatal("unexpected record", r, ok)
+	}
+
+	if rs := c.GetAll("ts", record{ts: 30}); len(rs) != 2 || rs[0].id != 1 || rs[1].id != 3 {
+		t.Fatal("unexpected records", rs)
+	}
+
+	ids := []int{}
+	c.AscendRange("ts", record{ts: 15}, record{ts: 30}, func(r record) bool {
+		ids = append(ids, r.id)
+		return true
+	})
+
+	if !reflect.DeepEqual(ids, []int{4}) {
+		t.Fatal("unexpected range", ids)
+	}
+
+	// update moves record in all indexes
+	if err := c.Update(record{1, 5, "e"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := c.Get("name", record{name: "c"}); ok {
+		t.Fatal("old name is found")
+	}
+
+	if ids := recordIDs(c, "ts"); !reflect.DeepEqual(ids, []int{1, 2, 4, 3}) {
+		t.Fatal("unexpected ts order", ids)
+	}
+
+	if err := c.Update(record{1, 5, "a"}); err != rbt.ErrDuplicate {
+		t.Fatal("duplicate name is updated", err)
+	}
+
+	if r, _ := c.Get("id", record{id: 1}); r.name != "e" {
+		t.Fatal("record is changed by failed update", r)
+	}
+
+	if err := c.Update(record{42, 0, "z"}); err != rbt.ErrNotFound {
+		t.Fatal("missing record is updated", err)
+	}
+
+	if !c.Delete(record{id: 3}) || c.Delete(record{id: 3}) {
+		t.Fatal("unexpected delete")
+	}
+
+	if rs := c.GetAll("ts", record{ts: 30}); len(rs) != 0 {
+		t.Fatal("deleted record is found", rs)
+	}
+
+	for _, index := range []string{"id", "ts", "name"} {
+		if ids := recordIDs(c, index); len(ids) != 3 {
+			t.Fatal("indexes are not in sync", index, ids)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package rbt
+
+import (
+	"constraints"
+
+	"gotest.com/rbt/compare"
+)
+
+// iter walks nodes of Tree in order. It keeps path from root to the current
+// node instead of following Parent links, that are not valid for nodes
+// shared with a clone, see Tree.Clone. Zero iter has no current node.
+type iter[T constraints.Ordered] struct {
+	path []*Node[T]
+}
+
+// node returns current node or nil.
+func (it *iter[T]) node() *Node[T] {
+	if len(it.path) == 0 {
+		return nil
+	}
+
+	return it.path[len(it.path)-1]
+}
+
+// min moves it to min node of tree with root n and returns it.
+func (it *iter[T]) min(n *Node[T]) *Node[T] {
+	it.path = it.path[:0]
+	return it.down(n, false)
+}
+
+// max moves it to max node of tree with root n and returns it.
+func (it *iter[T]) max(n *Node[T]) *Node[T] {
+	it.path = it.path[:0]
+	return it.down(n, true)
+}
+
+// lowerBound moves it to the first node that is not less than v in tree
+// with root n and returns it.
+func (it *iter[T]) lowerBound(n *Node[T], v T) *Node[T] {
+	it.path = it.path[:0]
+
+	lb := 0
+	for n != nil {
+		it.path = append(it.path, n)
+		if compare.Ordered(n.Value, v) < 0 {
+			n = n.Right
+		} else {
+			lb = len(it.path)
+			n = n.Left
+		}
+	}
+
+	it.path = it.path[:lb]
+
+	return it.node()
+}
+
+// next moves it to the next node and returns it.
+func (it *iter[T]) next() *Node[T] {
+	n := it.node()
+	if n == nil {
+		return nil
+	}
+
+	if n.Right != nil {
+		return it.down(n.Right, false)
+	}
+
+	return it.up(false)
+}
+
+// prev moves it to the previous node and returns it.
+func (it *iter[T]) prev() *Node[T] {
+	n := it.node()
+	if n == nil {
+		return nil
+	}
+
+	if n.Left != nil {
+		return it.down(n.Left, true)
+	}
+
+	return it.up(true)
+}
+
+// down goes from n to the most left or right node of its subtree.
+func (it *iter[T]) down(n *Node[T], right bool) *Node[T] {
+	for n != nil {
+		it.path = append(it.path, n)
+		if right {
+			n = n.Right
+		} else {
+			n = n.Left
+		}
+	}
+
+	return it.node()
+}
+
+// up goes up to the first ancestor which left subtree has current node,
+// that is the next node, or which right subtree has it if prev is true.
+func (it *iter[T]) up(prev bool) *Node[T] {
+	for {
+		n := it.node()
+		it.path = it.path[:len(it.path)-1]
+
+		p := it.node()
+		if p == nil || !prev && p.Left == n || prev && p.Right == n {
+			return p
+		}
+	}
+}
+
+// iterCmp walks nodes of TreeCmp in order, see iter.
+type iterCmp[T any] struct {
+	path []*NodeCmp[T]
+}
+
+// node returns current node or nil.
+func (it *iterCmp[T]) node() *NodeCmp[T] {
+	if len(it.path) == 0 {
+		return nil
+	}
+
+	return it.path[len(it.path)-1]
+}
+
+// min moves it to min node of tree with root n and returns it.
+func (it *iterCmp[T]) min(n *NodeCmp[T]) *NodeCmp[T] {
+	it.path = it.path[:0]
+	return it.down(n, false)
+}
+
+// max moves it to max node of tree with root n and returns it.
+func (it *iterCmp[T]) max(n *NodeCmp[T]) *NodeCmp[T] {
+	it.path = it.path[:0]
+	return it.down(n, true)
+}
+
+// lowerBound moves it to the first node that is not less than v in tree
+// with root n and returns it.
+func (it *iterCmp[T]) lowerBound(n *NodeCmp[T], v T, cmp func(a, b T) int) *NodeCmp[T] {
+	it.path = it.path[:0]
+
+	lb := 0
+	for n != nil {
+		it.path = append(it.path, n)
+		if cmp(n.Value, v) < 0 {
+			n = n.Right
+		} else {
+			lb = len(it.path)
+			n = n.Left
+		}
+	}
+
+	it.path = it.path[:lb]
+
+	return it.node()
+}
+
+// next moves it to the next node and returns it.
+func (it *iterCmp[T]) next() *NodeCmp[T] {
+	n := it.node()
+	if n == nil {
+		return nil
+	}
+
+	if n.Right != nil {
+		return it.down(n.Right, false)
+	}
+
+	return it.up(false)
+}
+
+// prev moves it to the previous node and returns it.
+func (it *iterCmp[T]) prev() *NodeCmp[T] {
+	n := it.node()
+	if n == nil {
+		return nil
+	}
+
+	if n.Left != nil {
+		return it.down(n.Left, true)
+	}
+
+	return it.up(true)
+}
+
+// down goes from n to the most left or right node of its subtree.
+func (it *iterCmp[T]) down(n *NodeCmp[T], right bool) *NodeCmp[T] {
+	for n != nil {
+		it.path = append(it.path, n)
+		if right {
+			n = n.Right
+		} else {
+			n = n.Left
+		}
+	}
+
+	return it.node()
+}
+
+// up goes up to the first ancestor which left subtree has current node,
+// that is the next node, or which right subtree has it if prev is true.
+func (it *iterCmp[T]) up(prev bool) *NodeCmp[T] {
+	for {
+		n := it.node()
+		it.path = it.path[:len(it.path)-1]
+
+		p := it.node()
+		if p == nil || !prev && p.Left == n || prev && p.Right == n {
+			return p
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package rbt
+
+import (
+	"constraints"
+
+	"gotest.com/rbt/compare"
+)
+
+// LLRB represents left-leaning red-black tree (Sedgewick's variant).
+// Red nodes are always left children, that makes it isomorphic to 2-3 tree
+// and needs less cases than Tree, but it makes more rotations.
+type LLRB[T constraints.Ordered] struct {
+	Root  *LLRBNode[T]
+	count int
+}
+
+type LLRBNode[T constraints.Ordered] struct {
+	Left  *LLRBNode[T]
+	Right *LLRBNode[T]
+	Red   bool
+	Value T
+}
+
+// Insert inserts v to t.
+func (t *LLRB[T]) Insert(v T) {
+	t.Root = t.Root.insert(v)
+	t.Root.Red = false
+	t.count++
+}
+
+// Delete deletes v from t and returns true if v was found.
+func (t *LLRB[T]) Delete(v T) bool {
+	if !t.Contains(v) {
+		return false
+	}
+
+	if !t.Root.Left.red() && !t.Root.Right.red() {
+		t.Root.Red = true
+	}
+
+	t.Root = t.Root.delete(v)
+	if t.Root != nil {
+		t.Root.Red = false
+	}
+
+	t.count--
+	return true
+}
+
+// Contains returns true if t contains value v.
+func (t *LLRB[T]) Contains(v T) bool {
+	n := t.Root
+	for n != nil {
+		c := compare.Ordered(v, n.Value)
+		if c == 0 {
+			return true
+		} else if c > 0 {
+			n = n.Right
+		} else {
+			n = n.Left
+		}
+	}
+
+	return false
+}
+
+// Min returns min value in t or false if t is empty.
+func (t *LLRB[T]) Min() (T, bool) {
+	var zero T
+	if t.Root == nil {
+		return zero, false
+	}
+
+	return t.Root.min().Value, true
+}
+
+// Max returns max value in t or false if t is empty.
+func (t *LLRB[T]) Max() (T, bool) {
+	var zero T
+	if t.Root == nil {
+		return zero, false
+	}
+
+	n := t.Root
+	for n.Right != nil {
+		n = n.Right
+	}
+
+	return n.Value, true
+}
+
+// Len returns number of values in t.
+func (t *LLRB[T]) Len() int {
+	return t.count
+}
+
+// Ascend calls fn for values of t in ascending order until fn returns false.
+func (t *LLRB[T]) Ascend(fn func(v T) bool) {
+	t.Root.ascend(fn)
+}
+
+// Black returns true if node is black. Nil node is considered black.
+func (n *LLRBNode[T]) Black() bool {
+	return !n.red()
+}
+
+func (n *LLRBNode[T]) red() bool {
+	return n != nil && n.Red
+}
+
+func (n *LLRBNode[T]) rotateLeft() *LLRBNode[T] {
+	c := n.Right
+	n.Right = c.Left
+	c.Left = n
+	c.Red = n.Red
+	n.Red = true
+
+	return c
+}
+
+func (n *LLRBNode[T]) rotateRight() *LLRBNode[T] {
+	b := n.Left
+	n.Left = b.Right
+	b.Right = n
+	b.Red = n.Red
+	n.Red = true
+
+	return b
+}
+
+func (n *LLRBNode[T]) flipColors() {
+	n.Red = !n.Red
+	n.Left.Red = !n.Left.Red
+	n.Right.Red = !n.Right.Red
+}
+
+// fixUp restores left-leaning red-black properties of n on the way up.
+func (n *LLRBNode[T]) fixUp() *LLRBNode[T] {
+	if n.Right.red() && !n.Left.red() {
+		n = n.rotateLeft()
+	}
+
+	if n.Left.red() && n.Left.Left.red() {
+		n = n.rotateRight()
+	}
+
+	if n.Left.red() && n.Right.red() {
+		n.flipColors()
+	}
+
+	return n
+}
+
+func (n *LLRBNode[T]) insert(v T) *LLRBNode[T] {
+	if n == nil {
+		return &LLRBNode[T]{
+			Value: v,
+			Red:   true,
+		}
+	}
+
+	if compare.Ordered(v, n.Value) > 0 {
+		n.Right = n.Right.insert(v)
+	} else {
+		n.Left = n.Left.insert(v)
+	}
+
+	return n.fixUp()
+}
+
+// moveRedLeft makes n.Left or one of its children red, n must be red.
+func (n *LLRBNode[T]) moveRedLeft() *LLRBNode[T] {
+	n.flipColors()
+	if n.Right.Left.red() {
+		n.Right = n.Right.rotateRight()
+		n = n.rotateLeft()
+		n.flipColors()
+	}
+
+	return n
+}
+
+// moveRedRight makes n.Right or one of its children red, n must be red.
+func (n *LLRBNode[T]) moveRedRight() *LLRBNode[T] {
+	n.flipColors()
+	if n.Left.Left.red() {
+		n = n.rotateRight()
+		n.flipColors()
+	}
+
+	return n
+}
+
+func (n *LLRBNode[T]) deleteMin() *LLRBNode[T] {
+	if n.Left == nil {
+		return nil
+	}
+
+	if !n.Left.red() && !n.Left.Left.red() {
+		n = n.moveRedLeft()
+	}
+
+	n.Left = n.Left.deleteMin()
+
+	return n.fixUp()
+}
+
+// delete deletes v from subtree n, v must be in subtree.
+func (n *LLRBNode[T]) delete(v T) *LLRBNode[T] {
+	if compare.Ordered(v, n.Value) < 0 {
+		if !n.Left.red() && !n.Left.Left.red() {
+			n = n.moveRedLeft()
+		}
+
+		n.Left = n.Left.delete(v)
+	} else {
+		// if n is rotated down it is in the right subtree even if v is equal
+		// to the value of new n, that is possible because of duplicates
+		m := n
+		if n.Left.red() {
+			n = n.rotateRight()
+		}
+
+		if n == m && compare.Ordered(v, n.Value) == 0 && n.Right == nil {
+			return nil
+		}
+
+		if !n.Right.red() && !n.Right.Left.red() {
+			n = n.moveRedRight()
+		}
+
+		if n == m && compare.Ordered(v, n.Value) == 0 {
+			n.Value = n.Right.min().Value
+			n.Right = n.Right.deleteMin()
+		} else {
+			n.Right = n.Right.delete(v)
+		}
+	}
+
+	return n.fixUp()
+}
+
+func (n *LLRBNode[T]) min() *LLRBNode[T] {
+	for n.Left != nil {
+		n = n.Left
+	}
+
+	return n
+}
+
+func (n *LLRBNode[T]) ascend(fn func(v T) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	return n.Left.ascend(fn) && fn(n.Value) && n.Right.ascend(fn)
+}
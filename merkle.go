@@ -0,0 +1,238 @@
+package rbt
+
+import (
+	"gotest.com/rbt/compare"
+)
+
+// KeyRange is range of values [Lo, Hi). LoInf and HiInf mean that range
+// is not bounded from below or above, in that case Lo or Hi are ignored.
+type KeyRange[T any] struct {
+	Lo, Hi       T
+	LoInf, HiInf bool
+}
+
+// Peer is a remote side of reconciliation. It is implemented by Tree and
+// TreeCmp with enabled subtree hashes, so peer in the same process can be
+// used directly, otherwise implementation should transport calls to the remote tree.
+type Peer[T any] interface {
+	// RangeHash returns hash of values in range r.
+	RangeHash(r KeyRange[T]) uint64
+}
+
+// EnableSubtreeHash turns on hashing of subtrees in t. h is a hash of
+// single value, values that are equal must have equal hashes. Every node keeps
+// hash of its subtree that is updated by insert, delete and rotations.
+// Subtree hash is a sum of values hashes rather than hash of children hashes, so
+// hash of any range of values does not depend on shape of tree and trees
+// with same values can be compared by range in O(log n).
+// EnableSubtreeHash takes O(n) to hash values that are already in t.
+func (t *Tree[T]) EnableSubtreeHash(h func(v T) uint64) {
+	t.ownAll()
+	t.hash = h
+	t.aug = true
+	t.Root.rehash(t.hashOf)
+}
+
+// SubtreeHash returns hash of all values in t.
+func (t *Tree[T]) SubtreeHash() uint64 {
+	return t.Root.hashSum()
+}
+
+// RangeHash returns hash of values in range r in O(log n).
+func (t *Tree[T]) RangeHash(r KeyRange[T]) uint64 {
+	s := t.Root.hashSum()
+	if !r.HiInf {
+		s = t.Root.hashBefore(r.Hi)
+	}
+
+	if !r.LoInf {
+		s -= t.Root.hashBefore(r.Lo)
+	}
+
+	return s
+}
+
+// Reconcile finds ranges of values that differ in t and peer. Ranges are
+// narrowed down by values of t in O(d log n) requests to peer where d is
+// number of differences. t must have subtree hashes enabled with the same hash
+// function as peer. Returned ranges are ordered and do not overlap.
+func (t *Tree[T]) Reconcile(peer Peer[T]) []KeyRange[T] {
+	rs := []KeyRange[T]{}
+	reconcile[T](t, peer, KeyRange[T]{LoInf: true, HiInf: true}, &rs)
+
+	return rs
+}
+
+func (t *Tree[T]) hashOf(v T) uint64 {
+	if t.hash == nil {
+		return 0
+	}
+
+	return mixHash(t.hash(v))
+}
+
+// splitKey returns value of t that is inside of r and is not equal to r.Lo.
+// Value is the highest such node in t, so it splits r roughly in half.
+func (t *Tree[T]) splitKey(r KeyRange[T]) (T, bool) {
+	n := t.Root
+	for n != nil {
+		if !r.LoInf && compare.Ordered(n.Value, r.Lo) <= 0 {
+			n = n.Right
+		} else if !r.HiInf && compare.Ordered(n.Value, r.Hi) >= 0 {
+			n = n.Left
+		} else {
+			return n.Value, true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+// rehash calculates hashes and sizes for all nodes of subtree n.
+func (n *Node[T]) rehash(h func(v T) uint64) {
+	if n == nil {
+		return
+	}
+
+	n.Left.rehash(h)
+	n.Right.rehash(h)
+	n.hash = h(n.Value)
+	n.update()
+}
+
+// hashBefore returns sum of hashes of values less than v in subtree n.
+func (n *Node[T]) hashBefore(v T) uint64 {
+	s := uint64(0)
+	for n != nil {
+		if compare.Ordered(n.Value, v) < 0 {
+			s += n.hash + n.Left.hashSum()
+			n = n.Right
+		} else {
+			n = n.Left
+		}
+	}
+
+	return s
+}
+
+// EnableSubtreeHash turns on hashing of subtrees in t, see Tree.EnableSubtreeHash.
+func (t *TreeCmp[T]) EnableSubtreeHash(h func(v T) uint64) {
+	t.ownAll()
+	t.hash = h
+	t.aug = true
+	t.Root.rehash(t.hashOf)
+}
+
+// SubtreeHash returns hash of all values in t.
+func (t *TreeCmp[T]) SubtreeHash() uint64 {
+	return t.Root.hashSum()
+}
+
+// RangeHash returns hash of values in range r in O(log n).
+func (t *TreeCmp[T]) RangeHash(r KeyRange[T]) uint64 {
+	s := t.Root.hashSum()
+	if !r.HiInf {
+		s = t.Root.hashBefore(r.Hi, t.Cmp)
+	}
+
+	if !r.LoInf {
+		s -= t.Root.hashBefore(r.Lo, t.Cmp)
+	}
+
+	return s
+}
+
+// Reconcile finds ranges of values that differ in t and peer, see Tree.Reconcile.
+func (t *TreeCmp[T]) Reconcile(peer Peer[T]) []KeyRange[T] {
+	rs := []KeyRange[T]{}
+	reconcile[T](t, peer, KeyRange[T]{LoInf: true, HiInf: true}, &rs)
+
+	return rs
+}
+
+func (t *TreeCmp[T]) hashOf(v T) uint64 {
+	if t.hash == nil {
+		return 0
+	}
+
+	return mixHash(t.hash(v))
+}
+
+// splitKey returns value of t that is inside of r and is not equal to r.Lo.
+func (t *TreeCmp[T]) splitKey(r KeyRange[T]) (T, bool) {
+	n := t.Root
+	for n != nil {
+		if !r.LoInf && t.Cmp(n.Value, r.Lo) <= 0 {
+			n = n.Right
+		} else if !r.HiInf && t.Cmp(n.Value, r.Hi) >= 0 {
+			n = n.Left
+		} else {
+			return n.Value, true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+// rehash calculates hashes and sizes for all nodes of subtree n.
+func (n *NodeCmp[T]) rehash(h func(v T) uint64) {
+	if n == nil {
+		return
+	}
+
+	n.Left.rehash(h)
+	n.Right.rehash(h)
+	n.hash = h(n.Value)
+	n.update()
+}
+
+// hashBefore returns sum of hashes of values less than v in subtree n.
+func (n *NodeCmp[T]) hashBefore(v T, cmp func(a, b T) int) uint64 {
+	s := uint64(0)
+	for n != nil {
+		if cmp(n.Value, v) < 0 {
+			s += n.hash + n.Left.hashSum()
+			n = n.Right
+		} else {
+			n = n.Left
+		}
+	}
+
+	return s
+}
+
+type reconciler[T any] interface {
+	Peer[T]
+	splitKey(r KeyRange[T]) (T, bool)
+}
+
+// reconcile compares hashes of range r in t and peer, if they differ
+// range is split by value of t until it can not be split anymore.
+func reconcile[T any](t reconciler[T], peer Peer[T], r KeyRange[T], rs *[]KeyRange[T]) {
+	if t.RangeHash(r) == peer.RangeHash(r) {
+		return
+	}
+
+	k, ok := t.splitKey(r)
+	if !ok {
+		*rs = append(*rs, r)
+		return
+	}
+
+	reconcile(t, peer, KeyRange[T]{Lo: r.Lo, LoInf: r.LoInf, Hi: k}, rs)
+	reconcile(t, peer, KeyRange[T]{Lo: k, Hi: r.Hi, HiInf: r.HiInf}, rs)
+}
+
+// mixHash spreads bits of h (splitmix64 finalizer), so that sums of poor
+// hashes like identity for integers do not collide easily.
+func mixHash(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+
+	return h
+}
@@ -0,0 +1,115 @@
+package rbt_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"gotest.com/rbt"
+	"gotest.com/rbt/compare"
+)
+
+func intHash(v int) uint64 {
+	return uint64(v)
+}
+
+func TestSubtreeHash(t *testing.T) {
+	a := &rbt.Tree[int]{}
+	a.EnableSubtreeHash(intHash)
+
+	vs := []int{}
+	for i := 0; i < 200; i++ {
+		v := rand.Intn(1000)
+		vs = append(vs, v)
+		a.Insert(v)
+	}
+
+	for _, v := range vs[:100] {
+		a.Delete(v)
+	}
+
+	err := checkTree(a.Root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// same values inserted in other order before enabling hashes
+	b := &rbt.Tree[int]{}
+	for _, i := range rand.Perm(100) {
+		b.Insert(vs[100+i])
+	}
+
+	b.EnableSubtreeHash(intHash)
+
+	if a.SubtreeHash() != b.SubtreeHash() {
+		t.Fatal("hash depends on shape of tree")
+	}
+
+	r := rbt.KeyRange[int]{Lo: 100, Hi: 500}
+	if a.RangeHash(r) != b.RangeHash(r) {
+		t.Fatal("range hash depends on shape of tree")
+	}
+
+	b.Insert(300)
+	if a.RangeHash(r) == b.RangeHash(r) {
+		t.Fatal("range hash is not changed")
+	}
+
+	if a.RangeHash(rbt.KeyRange[int]{Lo: 301, HiInf: true}) != b.RangeHash(rbt.KeyRange[int]{Lo: 301, HiInf: true}) {
+		t.Fatal("hash is changed outside of range")
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	a := &rbt.Tree[int]{}
+	a.EnableSubtreeHash(intHash)
+
+	for i := 0; i < 1000; i++ {
+		a.Insert(i * 2)
+	}
+
+	b := a.Clone()
+	if rs := a.Reconcile(b); len(rs) != 0 {
+		t.Fatal("unexpected differences", rs)
+	}
+
+	b.Delete(100)
+	b.Insert(777)
+	b.Insert(5000)
+
+	rs := a.Reconcile(b)
+	if len(rs) == 0 || len(rs) > 3 {
+		t.Fatal("unexpected number of differences", rs)
+	}
+
+	for _, v := range []int{100, 777, 5000} {
+		found := false
+		for _, r := range rs {
+			if (r.LoInf || v >= r.Lo) && (r.HiInf || v < r.Hi) {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Fatal("difference is not found", v, rs)
+		}
+	}
+}
+
+func TestReconcileCmp(t *testing.T) {
+	cmp := compare.Ordered[int]
+
+	a := &rbt.TreeCmp[int]{Cmp: cmp}
+	a.EnableSubtreeHash(intHash)
+
+	for i := 0; i < 100; i++ {
+		a.Insert(i)
+	}
+
+	b := a.Clone()
+	b.Delete(42)
+
+	rs := a.Reconcile(b)
+	if len(rs) != 1 || rs[0].LoInf || rs[0].Lo != 42 || rs[0].HiInf || rs[0].Hi != 43 {
+		t.Fatal("unexpected differences", rs)
+	}
+}
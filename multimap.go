@@ -0,0 +1,120 @@
+package rbt
+
+import (
+	"constraints"
+
+	"gotest.com/rbt/compare"
+)
+
+// MultiMap represents ordered map from key to many values. Values are kept
+// in TreeCmp ordered by key and then by insertion, so values of a key are
+// returned in order they were added. Zero MultiMap is ready to use.
+type MultiMap[K constraints.Ordered, V comparable] struct {
+	tree TreeCmp[multiEntry[K, V]]
+	seq  uint64 // insertion number of last added value, starts from 1
+}
+
+type multiEntry[K constraints.Ordered, V comparable] struct {
+	key   K
+	seq   uint64
+	value V
+}
+
+func cmpMultiEntry[K constraints.Ordered, V comparable](a, b multiEntry[K, V]) int {
+	if c := compare.Ordered(a.key, b.key); c != 0 {
+		return c
+	} else if a.seq < b.seq {
+		return -1
+	} else if a.seq > b.seq {
+		return 1
+	}
+
+	return 0
+}
+
+// Add adds value v to key k.
+func (m *MultiMap[K, V]) Add(k K, v V) {
+	m.init()
+	m.seq++
+	m.tree.Insert(multiEntry[K, V]{
+		key:   k,
+		seq:   m.seq,
+		value: v,
+	})
+}
+
+// GetAll returns values of key k in order they were added or nil if there are no values.
+func (m *MultiMap[K, V]) GetAll(k K) []V {
+	var vs []V
+	for n := m.first(k); n != nil && compare.Ordered(n.Value.key, k) == 0; n = n.Successor() {
+		vs = append(vs, n.Value.value)
+	}
+
+	return vs
+}
+
+// CountKey returns number of values of key k.
+func (m *MultiMap[K, V]) CountKey(k K) int {
+	c := 0
+	for n := m.first(k); n != nil && compare.Ordered(n.Value.key, k) == 0; n = n.Successor() {
+		c++
+	}
+
+	return c
+}
+
+// DeleteValue deletes the earliest added value v of key k and returns
+// false if there is no such value.
+func (m *MultiMap[K, V]) DeleteValue(k K, v V) bool {
+	for n := m.first(k); n != nil && compare.Ordered(n.Value.key, k) == 0; n = n.Successor() {
+		if n.Value.value == v {
+			m.tree.Delete(n.Value)
+			return true
+		}
+	}
+
+	return false
+}
+
+// DeleteAll deletes all values of key k and returns number of deleted values.
+func (m *MultiMap[K, V]) DeleteAll(k K) int {
+	es := []multiEntry[K, V]{}
+	for n := m.first(k); n != nil && compare.Ordered(n.Value.key, k) == 0; n = n.Successor() {
+		es = append(es, n.Value)
+	}
+
+	for _, e := range es {
+		m.tree.Delete(e)
+	}
+
+	return len(es)
+}
+
+// Len returns number of values of all keys.
+func (m *MultiMap[K, V]) Len() int {
+	return m.tree.Len()
+}
+
+// Ascend calls fn for keys in ascending order and for values of every key
+// in order they were added until fn returns false.
+func (m *MultiMap[K, V]) Ascend(fn func(k K, v V) bool) {
+	m.tree.Ascend(func(e multiEntry[K, V]) bool {
+		return fn(e.key, e.value)
+	})
+}
+
+func (m *MultiMap[K, V]) init() {
+	if m.tree.Cmp == nil {
+		m.tree.Cmp = cmpMultiEntry[K, V]
+	}
+}
+
+// first returns node of the earliest added value of key k or node of the
+// next key if k has no values.
+func (m *MultiMap[K, V]) first(k K) *NodeCmp[multiEntry[K, V]] {
+	if m.tree.Cmp == nil {
+		return nil
+	}
+
+	return m.tree.Root.LowerBound(multiEntry[K, V]{key: k}, m.tree.Cmp)
+}
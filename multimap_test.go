@@ -0,0 +1,86 @@
+package rbt_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"gotest.com/rbt"
+)
+
+func TestMultiMap(t *testing.T) {
+	m := &rbt.MultiMap[string, int]{}
+
+	if m.GetAll("a") != nil || m.DeleteValue("a", 1) || m.DeleteAll("a") != 0 {
+		t.Fatal("empty multimap has values")
+	}
+
+	for i := 0; i < 5; i++ {
+		m.Add("b", i)
+		m.Add("a", 10-i)
+		m.Add("c", 1)
+	}
+
+	m.Add("b", 2)
+
+	if vs := m.GetAll("b"); !reflect.DeepEqual(vs, []int{0, 1, 2, 3, 4, 2}) {
+		t.Fatal("unexpected values", vs)
+	}
+
+	if !m.DeleteValue("b", 2) || m.DeleteValue("b", 42) || m.DeleteValue("x", 2) {
+		t.Fatal("unexpected delete")
+	}
+
+	// the earliest duplicate is deleted
+	if vs := m.GetAll("b"); !reflect.DeepEqual(vs, []int{0, 1, 3, 4, 2}) {
+		t.Fatal("unexpected values", vs)
+	}
+
+	if m.CountKey("a") != 5 || m.CountKey("b") != 5 || m.CountKey("x") != 0 {
+		t.Fatal("unexpected count")
+	}
+
+	if n := m.DeleteAll("c"); n != 5 || m.GetAll("c") != nil || m.Len() != 10 {
+		t.Fatal("unexpected delete all", n)
+	}
+
+	type kv struct {
+		k string
+		v int
+	}
+
+	got := []kv{}
+	m.Ascend(func(k string, v int) bool {
+		got = append(got, kv{k, v})
+		return true
+	})
+
+	expected := []kv{
+		{"a", 10}, {"a", 9}, {"a", 8}, {"a", 7}, {"a", 6},
+		{"b", 0}, {"b", 1}, {"b", 3}, {"b", 4}, {"b", 2},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatal("unexpected order", got)
+	}
+}
+
+func TestMultiMapNaN(t *testing.T) {
+	m := &rbt.MultiMap[float64, int]{}
+	nan := math.NaN()
+
+	m.Add(1, 1)
+	m.Add(nan, 2)
+	m.Add(nan, 3)
+
+	if vs := m.GetAll(nan); !reflect.DeepEqual(vs, []int{2, 3}) || m.CountKey(nan) != 2 {
+		t.Fatal("unexpected values of NaN", vs)
+	}
+
+	if !m.DeleteValue(nan, 3) || m.DeleteAll(nan) != 1 || m.CountKey(nan) != 0 {
+		t.Fatal("unexpected delete of NaN")
+	}
+
+	if vs := m.GetAll(1); !reflect.DeepEqual(vs, []int{1}) || m.Len() != 1 {
+		t.Fatal("unexpected values", vs)
+	}
+}
@@ -0,0 +1,232 @@
+package rbt
+
+import (
+	"constraints"
+	"errors"
+	"sync"
+
+	"gotest.com/rbt/compare"
+)
+
+// ErrVersionCollected is returned by MVCC.Snapshot for version that
+// is already garbage collected or is not created yet.
+var ErrVersionCollected = errors.New("version is not available")
+
+// MVCC represents ordered set with multi-version concurrency control.
+// Every Insert and Delete creates new version, Snapshot returns read-only
+// view of any version that is not garbage collected yet, so long-running
+// readers see consistent data while writers keep changing the set.
+// Values are kept in TreeCmp with version where they were created and
+// deleted, deleted values are removed when no snapshot can see them.
+// MVCC is safe for concurrent use.
+type MVCC[T constraints.Ordered] struct {
+	mu      sync.RWMutex
+	tree    TreeCmp[mvccEntry[T]]
+	version uint64
+	oldest  uint64         // versions before oldest are collected
+	readers map[uint64]int // number of open snapshots by version
+	dead    []mvccEntry[T] // deleted entries ordered by deleted version
+}
+
+type mvccEntry[T constraints.Ordered] struct {
+	value   T
+	created uint64
+	deleted uint64 // zero if value is not deleted
+}
+
+// visible returns true if e is visible in version v.
+func (e mvccEntry[T]) visible(v uint64) bool {
+	return e.created <= v && (e.deleted == 0 || e.deleted > v)
+}
+
+func cmpMVCCEntry[T constraints.Ordered](a, b mvccEntry[T]) int {
+	if c := compare.Ordered(a.value, b.value); c != 0 {
+		return c
+	} else if a.created < b.created {
+		return -1
+	} else if a.created > b.created {
+		return 1
+	}
+
+	return 0
+}
+
+// Version returns current version of m.
+func (m *MVCC[T]) Version() uint64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.version
+}
+
+// Insert inserts v and returns new version.
+func (m *MVCC[T]) Insert(v T) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.init()
+	m.version++
+	m.tree.Insert(mvccEntry[T]{
+		value:   v,
+		created: m.version,
+	})
+
+	return m.version
+}
+
+// Delete deletes v and returns new version. If v is not found version
+// is not changed and false is returned.
+func (m *MVCC[T]) Delete(v T) (uint64, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.init()
+
+	n := m.find(v, m.version)
+	if n == nil {
+		return m.version, false
+	}
+
+	m.version++
+	n.Value.deleted = m.version
+	m.dead = append(m.dead, n.Value)
+
+	m.collect()
+
+	return m.version, true
+}
+
+// Snapshot returns read-only view of version. Snapshot must be closed,
+// versions that are seen by open snapshots are not garbage collected.
+func (m *MVCC[T]) Snapshot(version uint64) (*Snapshot[T], error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.init()
+
+	if version < m.oldest || version > m.version {
+		return nil, ErrVersionCollected
+	}
+
+	m.readers[version]++
+
+	return &Snapshot[T]{
+		m:       m,
+		version: version,
+	}, nil
+}
+
+func (m *MVCC[T]) init() {
+	if m.readers == nil {
+		m.readers = map[uint64]int{}
+		m.tree.Cmp = cmpMVCCEntry[T]
+	}
+}
+
+// find finds node with value v that is visible in version.
+func (m *MVCC[T]) find(v T, version uint64) *NodeCmp[mvccEntry[T]] {
+	n := m.tree.Root.LowerBound(mvccEntry[T]{value: v}, m.tree.Cmp)
+	for ; n != nil && compare.Ordered(n.Value.value, v) == 0; n = n.Successor() {
+		if n.Value.visible(version) {
+			return n
+		}
+	}
+
+	return nil
+}
+
+// collect removes deleted entries that are not visible in any open
+// snapshot. Version of new snapshot can not be less than the oldest
+// open snapshot or current version if there is no open snapshots.
+func (m *MVCC[T]) collect() {
+	h := m.version
+	for v := range m.readers {
+		if v < h {
+			h = v
+		}
+	}
+
+	m.oldest = h
+
+	i := 0
+	for ; i < len(m.dead) && m.dead[i].deleted <= h; i++ {
+		m.tree.Delete(m.dead[i])
+	}
+
+	m.dead = append(m.dead[:0], m.dead[i:]...)
+}
+
+// Snapshot is read-only view of MVCC version.
+type Snapshot[T constraints.Ordered] struct {
+	m       *MVCC[T]
+	version uint64
+	closed  bool
+}
+
+// Version returns version that is seen by s.
+func (s *Snapshot[T]) Version() uint64 {
+	return s.version
+}
+
+// Contains returns true if v is in s.
+func (s *Snapshot[T]) Contains(v T) bool {
+	s.m.mu.RLock()
+	defer s.m.mu.RUnlock()
+
+	return s.m.find(v, s.version) != nil
+}
+
+// Ascend calls fn for values of s in ascending order until fn returns false.
+// Writers are blocked while Ascend is running.
+func (s *Snapshot[T]) Ascend(fn func(v T) bool) {
+	s.Range(KeyRange[T]{LoInf: true, HiInf: true}, fn)
+}
+
+// Range calls fn for values of s in range r in ascending order until fn returns false.
+// Writers are blocked while Range is running.
+func (s *Snapshot[T]) Range(r KeyRange[T], fn func(v T) bool) {
+	s.m.mu.RLock()
+	defer s.m.mu.RUnlock()
+
+	n := s.m.tree.Root.Min()
+	if !r.LoInf {
+		n = s.m.tree.Root.LowerBound(mvccEntry[T]{value: r.Lo}, s.m.tree.Cmp)
+	}
+
+	for ; n != nil && (r.HiInf || compare.Ordered(n.Value.value, r.Hi) < 0); n = n.Successor() {
+		if n.Value.visible(s.version) && !fn(n.Value.value) {
+			return
+		}
+	}
+}
+
+// Len returns number of values in s in O(n).
+func (s *Snapshot[T]) Len() int {
+	l := 0
+	s.Ascend(func(v T) bool {
+		l++
+		return true
+	})
+
+	return l
+}
+
+// Close releases s, so values that are deleted after its version can be
+// garbage collected.
+func (s *Snapshot[T]) Close() {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	s.closed = true
+
+	s.m.readers[s.version]--
+	if s.m.readers[s.version] == 0 {
+		delete(s.m.readers, s.version)
+	}
+
+	s.m.collect()
+}
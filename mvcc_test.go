@@ -0,0 +1,131 @@
+package rbt_test
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"gotest.com/rbt"
+)
+
+func snapshotValues(s *rbt.Snapshot[int]) []int {
+	vs := []int{}
+	s.Ascend(func(v int) bool {
+		vs = append(vs, v)
+		return true
+	})
+
+	return vs
+}
+
+func TestMVCCSnapshot(t *testing.T) {
+	m := &rbt.MVCC[int]{}
+
+	for i := 0; i < 10; i++ {
+		m.Insert(i)
+	}
+
+	s, err := m.Snapshot(m.Version())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.Delete(3)
+	m.Delete(5)
+	v7 := m.Insert(3)
+	m.Insert(100)
+
+	expected := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	if got := snapshotValues(s); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("snapshot sees %v, expected %v", got, expected)
+	}
+
+	if !s.Contains(5) || s.Contains(100) || s.Len() != 10 {
+		t.Fatal("snapshot sees later changes")
+	}
+
+	s7, err := m.Snapshot(v7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = []int{0, 1, 2, 3, 4, 6, 7, 8, 9}
+	if got := snapshotValues(s7); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("snapshot sees %v, expected %v", got, expected)
+	}
+
+	r := []int{}
+	s7.Range(rbt.KeyRange[int]{Lo: 3, Hi: 7}, func(v int) bool {
+		r = append(r, v)
+		return true
+	})
+
+	if !reflect.DeepEqual(r, []int{3, 4, 6}) {
+		t.Fatal("unexpected range", r)
+	}
+
+	s.Close()
+	s7.Close()
+
+	// deleted values are collected, old versions are not available anymore
+	if _, err := m.Snapshot(v7); err != rbt.ErrVersionCollected {
+		t.Fatal("collected version is available", err)
+	}
+
+	cur, err := m.Snapshot(m.Version())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer cur.Close()
+
+	expected = []int{0, 1, 2, 3, 4, 6, 7, 8, 9, 100}
+	if got := snapshotValues(cur); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("snapshot sees %v, expected %v", got, expected)
+	}
+
+	if _, ok := m.Delete(42); ok {
+		t.Fatal("missing value is deleted")
+	}
+}
+
+func TestMVCCConcurrent(t *testing.T) {
+	m := &rbt.MVCC[int]{}
+	for i := 0; i < 100; i++ {
+		m.Insert(i)
+	}
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 100; i++ {
+			m.Delete(i)
+			m.Insert(i + 1000)
+		}
+	}()
+
+	for i := 0; i < 20; i++ {
+		s, err := m.Snapshot(m.Version())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// every version has exactly 100 or 99 values
+		// and the same values are seen by repeated reads
+		a := snapshotValues(s)
+		if len(a) < 99 || len(a) > 100 {
+			t.Fatal("inconsistent snapshot", len(a))
+		}
+
+		if b := snapshotValues(s); !reflect.DeepEqual(a, b) {
+			t.Fatal("snapshot is changed")
+		}
+
+		s.Close()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,31 @@
+package rbt
+
+// OrderedSet is common interface of ordered collections in this package,
+// it allows to switch between balancing strategies without changing callers.
+// Like Tree all implementations keep duplicate values, Delete removes
+// one of them.
+type OrderedSet[T any] interface {
+	// Insert inserts v.
+	Insert(v T)
+	// Delete deletes v and returns true if v was found.
+	Delete(v T) bool
+	// Contains returns true if v is in set.
+	Contains(v T) bool
+	// Min returns min value or false if set is empty.
+	Min() (T, bool)
+	// Max returns max value or false if set is empty.
+	Max() (T, bool)
+	// Len returns number of values.
+	Len() int
+	// Ascend calls fn for values in ascending order until fn returns false.
+	Ascend(fn func(v T) bool)
+}
+
+var (
+	_ OrderedSet[int] = (*Tree[int])(nil)
+	_ OrderedSet[int] = (*TreeCmp[int])(nil)
+	_ OrderedSet[int] = (*AVL[int])(nil)
+	_ OrderedSet[int] = (*Treap[int])(nil)
+	_ OrderedSet[int] = (*LLRB[int])(nil)
+	_ OrderedSet[int] = (*BTree[int])(nil)
+)
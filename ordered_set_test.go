@@ -0,0 +1,449 @@
+package rbt_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+
+	"gotest.com/rbt"
+	"gotest.com/rbt/compare"
+)
+
+type orderedSetCase struct {
+	name     string
+	new      func() rbt.OrderedSet[int]
+	newFloat func() rbt.OrderedSet[float64]
+	check    func(s rbt.OrderedSet[int]) error
+}
+
+var orderedSetCases = []orderedSetCase{
+	{
+		name: "Tree",
+		new: func() rbt.OrderedSet[int] {
+			return &rbt.Tree[int]{}
+		},
+		newFloat: func() rbt.OrderedSet[float64] {
+			return &rbt.Tree[float64]{}
+		},
+		check: func(s rbt.OrderedSet[int]) error {
+			return checkTree(s.(*rbt.Tree[int]).Root)
+		},
+	},
+	{
+		name: "TreeCmp",
+		new: func() rbt.OrderedSet[int] {
+			return &rbt.TreeCmp[int]{Cmp: compare.Ordered[int]}
+		},
+		newFloat: func() rbt.OrderedSet[float64] {
+			return &rbt.TreeCmp[float64]{Cmp: compare.Ordered[float64]}
+		},
+		check: func(s rbt.OrderedSet[int]) error {
+			return checkTreeCmp(s.(*rbt.TreeCmp[int]).Root)
+		},
+	},
+	{
+		name: "AVL",
+		new: func() rbt.OrderedSet[int] {
+			return &rbt.AVL[int]{}
+		},
+		newFloat: func() rbt.OrderedSet[float64] {
+			return &rbt.AVL[float64]{}
+		},
+		check: func(s rbt.OrderedSet[int]) error {
+			_, err := checkAVL(s.(*rbt.AVL[int]).Root)
+			return err
+		},
+	},
+	{
+		name: "Treap",
+		new: func() rbt.OrderedSet[int] {
+			return &rbt.Treap[int]{}
+		},
+		newFloat: func() rbt.OrderedSet[float64] {
+			return &rbt.Treap[float64]{}
+		},
+		check: func(s rbt.OrderedSet[int]) error {
+			return checkTreap(s.(*rbt.Treap[int]).Root)
+		},
+	},
+	{
+		name: "LLRB",
+		new: func() rbt.OrderedSet[int] {
+			return &rbt.LLRB[int]{}
+		},
+		newFloat: func() rbt.OrderedSet[float64] {
+			return &rbt.LLRB[float64]{}
+		},
+		check: func(s rbt.OrderedSet[int]) error {
+			return checkLLRB(s.(*rbt.LLRB[int]).Root)
+		},
+	},
+	{
+		name: "BTree",
+		new: func() rbt.OrderedSet[int] {
+			return &rbt.BTree[int]{Degree: 2}
+		},
+		newFloat: func() rbt.OrderedSet[float64] {
+			return &rbt.BTree[float64]{Degree: 2}
+		},
+		check: func(s rbt.OrderedSet[int]) error {
+			return checkBTree(s.(*rbt.BTree[int]), 2)
+		},
+	},
+}
+
+func TestOrderedSetConformance(t *testing.T) {
+	for _, c := range orderedSetCases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			testOrderedSet(t, c)
+		})
+	}
+}
+
+func TestOrderedSetNaN(t *testing.T) {
+	nan := math.NaN()
+
+	for _, c := range orderedSetCases {
+		s := c.newFloat()
+		for _, v := range []float64{1, nan, -1, nan, 2} {
+			s.Insert(v)
+		}
+
+		if !s.Contains(nan) || s.Contains(0) {
+			t.Fatal("unexpected contains", c.name)
+		}
+
+		// NaN is less than any other value
+		if m, ok := s.Min(); !ok || !math.IsNaN(m) {
+			t.Fatal("unexpected min", c.name, m)
+		}
+
+		if m, ok := s.Max(); !ok || m != 2 {
+			t.Fatal("unexpected max", c.name, m)
+		}
+
+		if !s.Delete(nan) || !s.Delete(nan) || s.Delete(nan) {
+			t.Fatal("unexpected delete of NaN", c.name)
+		}
+
+		vs := []float64{}
+		s.Ascend(func(v float64) bool {
+			vs = append(vs, v)
+			return true
+		})
+
+		if !reflect.DeepEqual(vs, []float64{-1, 1, 2}) || s.Len() != 3 {
+			t.Fatal("unexpected values", c.name, vs)
+		}
+	}
+}
+
+func testOrderedSet(t *testing.T, c orderedSetCase) {
+	s := c.new()
+	expected := []int{}
+
+	if _, ok := s.Min(); ok {
+		t.Fatal("min in empty set")
+	}
+
+	if _, ok := s.Max(); ok {
+		t.Fatal("max in empty set")
+	}
+
+	if s.Delete(1) {
+		t.Fatal("deleted from empty set")
+	}
+
+	for i := 0; i < 300; i++ {
+		v := rand.Intn(100)
+		if i%3 == 2 {
+			ok := s.Delete(v)
+			j := sort.SearchInts(expected, v)
+			found := j < len(expected) && expected[j] == v
+			if ok != found {
+				t.Fatal("unexpected delete result", v, ok)
+			}
+
+			if found {
+				expected = append(expected[:j], expected[j+1:]...)
+			}
+		} else {
+			s.Insert(v)
+			expected = append(expected, v)
+			sort.Ints(expected)
+		}
+
+		err := c.check(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if s.Len() != len(expected) {
+			t.Fatal("unexpected len", s.Len(), len(expected))
+		}
+
+		if !s.Contains(expected[0]) {
+			t.Fatal("min value is not found", expected[0])
+		}
+
+		if m, ok := s.Min(); !ok || m != expected[0] {
+			t.Fatal("unexpected min", m, expected[0])
+		}
+
+		if m, ok := s.Max(); !ok || m != expected[len(expected)-1] {
+			t.Fatal("unexpected max", m, expected[len(expected)-1])
+		}
+	}
+
+	vs := []int{}
+	s.Ascend(func(v int) bool {
+		vs = append(vs, v)
+		return true
+	})
+
+	if !reflect.DeepEqual(vs, expected) {
+		t.Fatalf("unexpected values %v, expected %v", vs, expected)
+	}
+
+	n := 0
+	s.Ascend(func(v int) bool {
+		n++
+		return n < 3
+	})
+
+	if n != 3 {
+		t.Fatal("ascend is not stopped", n)
+	}
+
+	if s.Contains(-1) {
+		t.Fatal("unexpected value found")
+	}
+
+	for _, v := range vs {
+		if !s.Delete(v) {
+			t.Fatal("value is not deleted", v)
+		}
+
+		err := c.check(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if s.Len() != 0 {
+		t.Fatal("non empty set after all")
+	}
+}
+
+func BenchmarkOrderedSetInsert(b *testing.B) {
+	for _, c := range orderedSetCases {
+		b.Run(c.name, func(b *testing.B) {
+			s := c.new()
+			for i := 0; i < b.N; i++ {
+				s.Insert(rand.Int())
+			}
+		})
+	}
+}
+
+// checkTreeCmp checks red-black properties of tree n same as checkTree.
+func checkTreeCmp[T any](n *rbt.NodeCmp[T]) error {
+	if n == nil {
+		return nil
+	}
+
+	if n.Red {
+		return errors.New("root not black")
+	}
+
+	_, err := blackHeightCmp(n)
+	return err
+}
+
+func blackHeightCmp[T any](n *rbt.NodeCmp[T]) (int, error) {
+	if n == nil {
+		return 0, nil
+	}
+
+	if n.Parent != nil && n.Parent.Left != n && n.Parent.Right != n {
+		return 0, errors.New("wrong parent")
+	}
+
+	if n.Red && (!n.Left.Black() || !n.Right.Black()) {
+		return 0, errors.New("red node has red child")
+	}
+
+	bl, err := blackHeightCmp(n.Left)
+	if err != nil {
+		return 0, err
+	}
+
+	br, err := blackHeightCmp(n.Right)
+	if err != nil {
+		return 0, err
+	}
+
+	if bl != br {
+		return 0, fmt.Errorf("black height differs for %v; %d != %d", n.Value, bl, br)
+	}
+
+	if n.Black() {
+		return bl + 1, nil
+	}
+
+	return bl, nil
+}
+
+// checkAVL checks that heights of n are valid and balanced and returns height of n.
+func checkAVL(n *rbt.AVLNode[int]) (int, error) {
+	if n == nil {
+		return 0, nil
+	}
+
+	lh, err := checkAVL(n.Left)
+	if err != nil {
+		return 0, err
+	}
+
+	rh, err := checkAVL(n.Right)
+	if err != nil {
+		return 0, err
+	}
+
+	if lh-rh > 1 || rh-lh > 1 {
+		return 0, fmt.Errorf("unbalanced node %v; %d, %d", n.Value, lh, rh)
+	}
+
+	h := lh + 1
+	if rh > lh {
+		h = rh + 1
+	}
+
+	if n.Height != h {
+		return 0, fmt.Errorf("wrong height for %v; %d != %d", n.Value, n.Height, h)
+	}
+
+	return h, nil
+}
+
+// checkTreap checks that priorities of n form a heap.
+func checkTreap(n *rbt.TreapNode[int]) error {
+	if n == nil {
+		return nil
+	}
+
+	for _, c := range []*rbt.TreapNode[int]{n.Left, n.Right} {
+		if c == nil {
+			continue
+		}
+
+		if c.Priority > n.Priority {
+			return fmt.Errorf("priority of %v is greater than parent's", c.Value)
+		}
+
+		err := checkTreap(c)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkLLRB checks red-black properties of n and that red nodes lean left.
+func checkLLRB(n *rbt.LLRBNode[int]) error {
+	if n == nil {
+		return nil
+	}
+
+	if n.Red {
+		return errors.New("root not black")
+	}
+
+	_, err := blackHeightLLRB(n)
+	return err
+}
+
+func blackHeightLLRB(n *rbt.LLRBNode[int]) (int, error) {
+	if n == nil {
+		return 0, nil
+	}
+
+	if !n.Right.Black() {
+		return 0, fmt.Errorf("right child of %v is red", n.Value)
+	}
+
+	if n.Red && !n.Left.Black() {
+		return 0, fmt.Errorf("red node %v has red child", n.Value)
+	}
+
+	bl, err := blackHeightLLRB(n.Left)
+	if err != nil {
+		return 0, err
+	}
+
+	br, err := blackHeightLLRB(n.Right)
+	if err != nil {
+		return 0, err
+	}
+
+	if bl != br {
+		return 0, fmt.Errorf("black height differs for %v; %d != %d", n.Value, bl, br)
+	}
+
+	if n.Black() {
+		return bl + 1, nil
+	}
+
+	return bl, nil
+}
+
+// checkBTree checks that all leaves of t are on the same level and that
+// nodes have allowed number of values for degree d.
+func checkBTree(t *rbt.BTree[int], d int) error {
+	if t.Root == nil {
+		return nil
+	}
+
+	_, err := checkBNode(t.Root, d, true)
+	return err
+}
+
+func checkBNode(n *rbt.BNode[int], d int, root bool) (int, error) {
+	if len(n.Values) > 2*d-1 || (!root && len(n.Values) < d-1) || len(n.Values) == 0 {
+		return 0, fmt.Errorf("wrong number of values %v", n.Values)
+	}
+
+	if !sort.IntsAreSorted(n.Values) {
+		return 0, fmt.Errorf("values are not sorted %v", n.Values)
+	}
+
+	if n.Children == nil {
+		return 1, nil
+	}
+
+	if len(n.Children) != len(n.Values)+1 {
+		return 0, fmt.Errorf("wrong number of children for %v", n.Values)
+	}
+
+	h := 0
+	for i, c := range n.Children {
+		ch, err := checkBNode(c, d, false)
+		if err != nil {
+			return 0, err
+		}
+
+		if i > 0 && ch != h {
+			return 0, fmt.Errorf("leaves on different levels under %v", n.Values)
+		}
+
+		h = ch
+	}
+
+	return h + 1, nil
+}
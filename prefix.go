@@ -0,0 +1,66 @@
+package rbt
+
+import (
+	"bytes"
+	"strings"
+)
+
+// PrefixRange calls fn for values of t that start with prefix in ascending
+// order until fn returns false.
+func PrefixRange[S ~string](t *Tree[S], prefix S, fn func(v S) bool) {
+	var it iter[S]
+	for n := it.lowerBound(t.Root, prefix); n != nil && strings.HasPrefix(string(n.Value), string(prefix)); n = it.next() {
+		if !fn(n.Value) {
+			return
+		}
+	}
+}
+
+// CountPrefix returns number of values of t that start with prefix in
+// O(log n) if t has EnableSubtreeSize, or in O(log n + k) for k counted
+// values otherwise.
+func CountPrefix[S ~string](t *Tree[S], prefix S) int {
+	end, ok := prefixEnd([]byte(prefix))
+
+	return t.countRange(KeyRange[S]{Lo: prefix, Hi: S(end), HiInf: !ok})
+}
+
+// PrefixRangeBytes calls fn for values of t that start with prefix in
+// ascending order until fn returns false. t must be ordered by bytes.Compare.
+func PrefixRangeBytes(t *TreeCmp[[]byte], prefix []byte, fn func(v []byte) bool) {
+	var it iterCmp[[]byte]
+	for n := it.lowerBound(t.Root, prefix, t.Cmp); n != nil && bytes.HasPrefix(n.Value, prefix); n = it.next() {
+		if !fn(n.Value) {
+			return
+		}
+	}
+}
+
+// CountPrefixBytes returns number of values of t that start with prefix
+// in O(log n) if t has EnableSubtreeSize, or in O(log n + k) for k counted
+// values otherwise. t must be ordered by bytes.Compare.
+func CountPrefixBytes(t *TreeCmp[[]byte], prefix []byte) int {
+	end, ok := prefixEnd(prefix)
+
+	return t.countRange(KeyRange[[]byte]{Lo: prefix, Hi: end, HiInf: !ok})
+}
+
+// prefixEnd returns the least key that is greater than all keys with prefix
+// p or false if there is no such key. Trailing 0xFF bytes can not be
+// incremented, so they are dropped and the previous byte is incremented.
+// Prefix of only 0xFF bytes (or empty one) has no upper bound.
+func prefixEnd(p []byte) ([]byte, bool) {
+	i := len(p) - 1
+	for i >= 0 && p[i] == 0xFF {
+		i--
+	}
+
+	if i < 0 {
+		return nil, false
+	}
+
+	end := append([]byte{}, p[:i+1]...)
+	end[i]++
+
+	return end, true
+}
@@ -0,0 +1,116 @@
+package rbt_test
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"gotest.com/rbt"
+)
+
+func TestPrefixRange(t *testing.T) {
+	testPrefixRange(t, false)
+	testPrefixRange(t, true)
+}
+
+// testPrefixRange checks prefix ranges and counts of trees with sizes
+// of subtrees kept or not kept.
+func testPrefixRange(t *testing.T, sized bool) {
+	tree := &rbt.Tree[string]{}
+	btree := &rbt.TreeCmp[[]byte]{Cmp: bytes.Compare}
+	if sized {
+		tree.EnableSubtreeSize()
+		btree.EnableSubtreeSize()
+	}
+
+	vs := []string{}
+
+	// short keys of few bytes, so prefixes share 0xFF tails often
+	alphabet := []byte{0, 'a', 'b', 0xFE, 0xFF}
+	for i := 0; i < 2000; i++ {
+		b := make([]byte, rand.Intn(5))
+		for j := range b {
+			b[j] = alphabet[rand.Intn(len(alphabet))]
+		}
+
+		tree.Insert(string(b))
+		btree.Insert(b)
+		vs = append(vs, string(b))
+	}
+
+	sort.Strings(vs)
+
+	// delete some values to check that sizes are kept
+	for i := 0; i < 200; i++ {
+		j := rand.Intn(len(vs))
+		tree.Delete(vs[j])
+		btree.Delete([]byte(vs[j]))
+		vs = append(vs[:j], vs[j+1:]...)
+	}
+
+	prefixes := []string{"", "a", "ab", "\xff", "\xff\xff", "a\xff", "b\xff\xff", "\xfe\xff", "\x00", "zzz"}
+	for _, p := range prefixes {
+		expected := []string{}
+		for _, v := range vs {
+			if strings.HasPrefix(v, p) {
+				expected = append(expected, v)
+			}
+		}
+
+		got := []string{}
+		rbt.PrefixRange(tree, p, func(v string) bool {
+			got = append(got, v)
+			return true
+		})
+
+		if len(got) != len(expected) || len(got) > 0 && !reflect.DeepEqual(got, expected) {
+			t.Fatalf("prefix %q: got %d values, expected %d", p, len(got), len(expected))
+		}
+
+		bgot := 0
+		rbt.PrefixRangeBytes(btree, []byte(p), func(v []byte) bool {
+			if !bytes.HasPrefix(v, []byte(p)) {
+				t.Fatalf("prefix %q: unexpected value %q", p, v)
+			}
+
+			bgot++
+			return true
+		})
+
+		if bgot != len(expected) {
+			t.Fatalf("prefix %q: got %d values, expected %d", p, bgot, len(expected))
+		}
+
+		if c := rbt.CountPrefix(tree, p); c != len(expected) {
+			t.Fatalf("prefix %q: count %d, expected %d", p, c, len(expected))
+		}
+
+		if c := rbt.CountPrefixBytes(btree, []byte(p)); c != len(expected) {
+			t.Fatalf("prefix %q: count %d, expected %d", p, c, len(expected))
+		}
+	}
+}
+
+func TestPrefixRangeStop(t *testing.T) {
+	tree := &rbt.Tree[string]{}
+	for _, v := range []string{"/api/v1/a", "/api/v2/a", "/api/v2/b", "/api/v2/c", "/api/v3"} {
+		tree.Insert(v)
+	}
+
+	got := []string{}
+	rbt.PrefixRange(tree, "/api/v2/", func(v string) bool {
+		got = append(got, v)
+		return len(got) < 2
+	})
+
+	if !reflect.DeepEqual(got, []string{"/api/v2/a", "/api/v2/b"}) {
+		t.Fatal("unexpected values", got)
+	}
+
+	if c := rbt.CountPrefix(tree, "/api/v2/"); c != 3 {
+		t.Fatal("unexpected count", c)
+	}
+}
@@ -3,22 +3,60 @@ package rbt
 import (
 	"constraints"
 	"fmt"
+	"sync/atomic"
+
+	"gotest.com/rbt/compare"
 )
 
-// Tree represents red-black tree.
+// Tree represents red-black tree. Values are compared with compare.Ordered,
+// so floats are ordered totally and NaN is less than any other value.
 type Tree[T constraints.Ordered] struct {
-	Root *Node[T]
+	Root  *Node[T]
+	owner *token
+	hash  func(v T) uint64
+	aug   bool // sizes and hashes of subtrees are kept, see EnableSubtreeSize
+	count int
+	min   *Node[T] // cached most left node, see PeekMin
+	max   *Node[T] // cached most right node, see PeekMax
 }
 
 func (t *Tree[T]) Insert(v T) {
+	t.insertNear(nil, v)
+}
+
+// insertNear inserts v starting search from node f of t, or from root if f
+// is nil, and returns node of v.
+func (t *Tree[T]) insertNear(f *Node[T], v T) *Node[T] {
+	h := t.hashOf(v)
+	t.count++
+	t.own()
+
 	if t.Root == nil {
 		t.Root = &Node[T]{
 			Value: v,
+			owner: t.owner,
+			hash:  h,
+			sum:   h,
+			size:  1,
 		}
-		return
+		t.min, t.max = t.Root, t.Root
+		return t.Root
 	}
 
-	top := t.Root.insert(v)
+	// f can be changed only if it is attached to t, otherwise search starts from root
+	var nn, top *Node[T]
+	if !t.attached(f) {
+		nn, top = t.Root.insert(v, h, t.aug)
+	} else {
+		nn, top = f.insertNear(v, h, t.aug)
+	}
+
+	// new min and max are attached to the old ones and stay their children
+	if t.min.Left == nn {
+		t.min = nn
+	} else if t.max.Right == nn {
+		t.max = nn
+	}
 
 	// insert can replace root - so check it
 	if top.Parent == nil {
@@ -26,6 +64,8 @@ func (t *Tree[T]) Insert(v T) {
 	} else if top.Parent.Parent == nil {
 		t.Root = top.Parent
 	}
+
+	return nn
 }
 
 func (t *Tree[T]) Delete(v T) bool {
@@ -34,18 +74,242 @@ func (t *Tree[T]) Delete(v T) bool {
 		return false
 	}
 
-	c := n.delete()
+	t.deleteNode(t.mutNode(n))
+
+	return true
+}
+
+// PeekMin returns min value in t in O(1) or false if t is empty.
+func (t *Tree[T]) PeekMin() (T, bool) {
+	return t.Min()
+}
 
-	// delete can replace root - so check it
-	if c == nil || c.Parent == nil {
-		t.Root = c
-	} else if c.Parent.Parent == nil {
-		t.Root = c.Parent
-	} else if c.Parent.Parent.Parent == nil {
-		t.Root = c.Parent.Parent
+// PeekMax returns max value in t in O(1) or false if t is empty.
+func (t *Tree[T]) PeekMax() (T, bool) {
+	return t.Max()
+}
+
+// PopMin deletes min value from t and returns it or false if t is empty.
+// Rebalancing after removal is amortised O(1).
+func (t *Tree[T]) PopMin() (T, bool) {
+	t.own()
+
+	n := t.min
+	if n == nil {
+		var zero T
+		return zero, false
 	}
 
-	return true
+	v := n.Value
+	t.deleteNode(n)
+
+	return v, true
+}
+
+// PopMax deletes max value from t and returns it or false if t is empty.
+// Rebalancing after removal is amortised O(1).
+func (t *Tree[T]) PopMax() (T, bool) {
+	t.own()
+
+	n := t.max
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+
+	v := n.Value
+	t.deleteNode(n)
+
+	return v, true
+}
+
+// deleteNode deletes n from t and keeps cached min and max nodes. n must
+// be owned by t, see mutNode.
+func (t *Tree[T]) deleteNode(n *Node[T]) {
+	t.count--
+
+	// min and max have at most one child, so they are removed physically
+	// and their neighbours are found in O(1). The child is copied before
+	// it is cached if it is shared, because delete changes its parent.
+	// Node with two children is replaced by value of its successor that can be max.
+	min, max := t.min, t.max
+	if n == min {
+		n.mut(n.Right)
+		min = n.Successor()
+	}
+
+	if n == max {
+		n.mut(n.Left)
+		max = n.Predecessor()
+	} else if n.Left != nil && n.Right != nil && n.Successor() == max {
+		max = n
+	}
+
+	c := n.delete(t.aug)
+
+	// delete can replace root and returned node can be deep
+	// in the tree (or be already removed) - so go up to the root
+	for c != nil && c.Parent != nil {
+		c = c.Parent
+	}
+	t.Root = c
+	t.min, t.max = min, max
+}
+
+// Clone returns a copy of t in O(1). Nodes stay shared by t and the copy,
+// a write into either tree copies only shared nodes that it changes
+// and nodes on the path from root to them, so the rest of nodes is still
+// shared. Clone does not change t, so it can be called concurrently with
+// readers of t.
+// When t copies a node it sets the copy as Parent of its children, so
+// Parent links of t stay valid and Successor and Predecessor work on its
+// nodes. The copy does not change Parent links of shared nodes, they lead
+// to nodes of t, so Successor and Predecessor must not be used on nodes of
+// the copy. Methods of the copy do not follow Parent links of shared nodes.
+func (t *Tree[T]) Clone() *Tree[T] {
+	c := *t
+	c.owner = &token{copy: true}
+	t.owner.share()
+
+	return &c
+}
+
+// attached returns true if n is node of t that can be changed and followed
+// by Parent links. Node that is shared with a clone, was copied since it was
+// found or was deleted from t is not attached. Nodes of other trees and of
+// Seq have other token or none.
+func (t *Tree[T]) attached(n *Node[T]) bool {
+	return n != nil && t.owner != nil && n.owner == t.owner && (n.Parent != nil || n == t.Root)
+}
+
+// own gives t new token on the first write or after Clone and makes root
+// of t owned by t, copying it if it is shared with a clone.
+// Paths to min and max are copied too, so cached min and max nodes are
+// owned and are never replaced by copies.
+func (t *Tree[T]) own() {
+	t.owner = t.owner.next()
+	if t.Root == nil || t.Root.owner == t.owner {
+		return
+	}
+
+	t.Root = t.Root.clone(nil, t.owner)
+	t.min, t.max = t.Root.mutMin(), t.Root.mutMax()
+}
+
+// mutNode returns node with value of n that can be changed. If n is shared
+// with a clone, it is found again from root copying nodes on the path.
+func (t *Tree[T]) mutNode(n *Node[T]) *Node[T] {
+	t.own()
+	if n.owner == t.owner {
+		return n
+	}
+
+	return t.Root.mutFind(n.Value)
+}
+
+// ownAll makes all nodes of t owned by t, copying nodes that are shared with a clone.
+func (t *Tree[T]) ownAll() {
+	t.own()
+	t.Root = t.Root.ownAll(nil, t.owner)
+	t.min, t.max = t.Root.Min(), t.Root.Max()
+}
+
+// Contains returns true if t contains value v.
+func (t *Tree[T]) Contains(v T) bool {
+	return t.Root.Find(v) != nil
+}
+
+// Find returns value of t that is equal to v or false if there is no such value.
+func (t *Tree[T]) Find(v T) (T, bool) {
+	n := t.Root.Find(v)
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+
+	return n.Value, true
+}
+
+// Min returns min value in t or false if t is empty.
+func (t *Tree[T]) Min() (T, bool) {
+	n := t.min
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+
+	return n.Value, true
+}
+
+// Max returns max value in t or false if t is empty.
+func (t *Tree[T]) Max() (T, bool) {
+	n := t.max
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+
+	return n.Value, true
+}
+
+// Len returns number of values in t.
+func (t *Tree[T]) Len() int {
+	return t.count
+}
+
+// EnableSubtreeSize turns on counting of nodes in subtrees of t, so
+// CountPrefix and View.Len take O(log n) and CountLess can be used on root
+// of t. Sizes are updated on the path to the root by every insert and delete,
+// so they are not kept by default. EnableSubtreeSize takes O(n).
+func (t *Tree[T]) EnableSubtreeSize() {
+	t.ownAll()
+	t.aug = true
+	t.Root.rehash(t.hashOf)
+}
+
+// countRange returns number of values of t in range r in O(log n) if sizes
+// of subtrees are kept, or in O(log n + k) for k values in r otherwise.
+func (t *Tree[T]) countRange(r KeyRange[T]) int {
+	if !t.aug {
+		var it iter[T]
+		n := it.min(t.Root)
+		if !r.LoInf {
+			n = it.lowerBound(t.Root, r.Lo)
+		}
+
+		c := 0
+		for ; n != nil && (r.HiInf || compare.Ordered(n.Value, r.Hi) < 0); n = it.next() {
+			c++
+		}
+
+		return c
+	}
+
+	c := t.count
+	if !r.HiInf {
+		c = t.Root.CountLess(r.Hi)
+	}
+
+	if !r.LoInf {
+		c -= t.Root.CountLess(r.Lo)
+	}
+
+	if c < 0 {
+		// lo is greater than hi
+		return 0
+	}
+
+	return c
+}
+
+// Ascend calls fn for values of t in ascending order until fn returns false.
+func (t *Tree[T]) Ascend(fn func(v T) bool) {
+	var it iter[T]
+	for n := it.min(t.Root); n != nil; n = it.next() {
+		if !fn(n.Value) {
+			return
+		}
+	}
 }
 
 func (t *Tree[T]) Height() int {
@@ -56,12 +320,52 @@ func (t *Tree[T]) Height() int {
 	return t.Root.Height()
 }
 
+// token identifies tree that owns nodes, see Clone. Nodes owned by tree
+// are not shared with other trees and only they are changed by the tree.
+// Clone marks token as shared and the tree takes new token on the next write.
+type token struct {
+	shared int32 // nodes are shared with a copy, it is accessed atomically
+	copy   bool  // token of tree that was made by Clone
+}
+
+// share marks nodes of o as shared, o can be nil.
+func (o *token) share() {
+	if o != nil {
+		atomic.StoreInt32(&o.shared, 1)
+	}
+}
+
+// next returns token for the next write into tree with token o. New token
+// is returned if tree has no token or its nodes are shared by Clone.
+func (o *token) next() *token {
+	if o == nil {
+		return &token{}
+	}
+
+	if atomic.LoadInt32(&o.shared) != 0 {
+		return &token{copy: o.copy}
+	}
+
+	return o
+}
+
+// adopts returns true if node owned by o sets itself as Parent of its child
+// owned by c. Copies made by Clone do not change Parent of shared nodes,
+// so Parent links stay valid in the tree that was cloned.
+func (o *token) adopts(c *token) bool {
+	return o == nil || !o.copy || o == c
+}
+
 type Node[T constraints.Ordered] struct {
 	Left   *Node[T]
 	Right  *Node[T]
 	Parent *Node[T]
 	Red    bool
 	Value  T
+	owner  *token
+	hash   uint64 // hash of Value, see EnableSubtreeHash
+	sum    uint64 // sum of hashes in subtree n
+	size   int    // number of nodes in subtree n, see EnableSubtreeSize
 }
 
 // Black returns true if node is black. Nil node is considered black.
@@ -76,9 +380,10 @@ func (n *Node[T]) Black() bool {
 // Find finds node with value v in subtree n.
 func (n *Node[T]) Find(v T) *Node[T] {
 	for n != nil {
-		if n.Value == v {
+		c := compare.Ordered(v, n.Value)
+		if c == 0 {
 			return n
-		} else if v > n.Value {
+		} else if c > 0 {
 			n = n.Right
 		} else {
 			n = n.Left
@@ -88,7 +393,40 @@ func (n *Node[T]) Find(v T) *Node[T] {
 	return n
 }
 
-// Finds node Successor or nil if there is no successor.
+// LowerBound finds first node with value that is not less than v in subtree n.
+func (n *Node[T]) LowerBound(v T) *Node[T] {
+	var lb *Node[T]
+	for n != nil {
+		if compare.Ordered(n.Value, v) < 0 {
+			n = n.Right
+		} else {
+			lb = n
+			n = n.Left
+		}
+	}
+
+	return lb
+}
+
+// CountLess returns number of values less than v in subtree n in O(log n).
+// Sizes of subtrees are kept only in trees with EnableSubtreeSize or
+// EnableSubtreeHash.
+func (n *Node[T]) CountLess(v T) int {
+	c := 0
+	for n != nil {
+		if compare.Ordered(n.Value, v) < 0 {
+			c += n.Left.subtreeSize() + 1
+			n = n.Right
+		} else {
+			n = n.Left
+		}
+	}
+
+	return c
+}
+
+// Finds node Successor or nil if there is no successor. Successor follows
+// Parent links, so it must not be used in cloned trees, see Tree.Clone.
 func (n *Node[T]) Successor() *Node[T] {
 	if n == nil {
 		return nil
@@ -107,6 +445,26 @@ func (n *Node[T]) Successor() *Node[T] {
 	return p
 }
 
+// Predecessor finds node predecessor or nil if there is no predecessor.
+// Like Successor, it must not be used in cloned trees.
+func (n *Node[T]) Predecessor() *Node[T] {
+	if n == nil {
+		return nil
+	}
+
+	if n.Left != nil {
+		return n.Left.Max()
+	}
+
+	p := n.Parent
+	for p != nil && n == p.Left {
+		n = p
+		p = p.Parent
+	}
+
+	return p
+}
+
 // Finds min (most left) value in tree. Or nil if tree is empty.
 func (n *Node[T]) Min() *Node[T] {
 	if n == nil {
@@ -133,24 +491,124 @@ func (n *Node[T]) Max() *Node[T] {
 	return n
 }
 
+// clone returns copy of n with parent p that is owned by o, children of
+// n are shared by n and the copy.
+func (n *Node[T]) clone(p *Node[T], o *token) *Node[T] {
+	c := &Node[T]{
+		Left:   n.Left,
+		Right:  n.Right,
+		Parent: p,
+		Red:    n.Red,
+		Value:  n.Value,
+		owner:  o,
+		hash:   n.hash,
+		sum:    n.sum,
+		size:   n.size,
+	}
+
+	// children stay shared, Parent links of them lead to the copy if it
+	// is owned by tree that keeps them valid, see token.adopts
+	if c.Left != nil && o.adopts(c.Left.owner) {
+		c.Left.Parent = c
+	}
+
+	if c.Right != nil && o.adopts(c.Right.owner) {
+		c.Right.Parent = c
+	}
+
+	return c
+}
+
+// mut returns child c of n that can be changed. If c is shared with
+// a clone, it is replaced by its copy owned by owner of n, so n must be
+// owned by tree that is changed.
+func (n *Node[T]) mut(c *Node[T]) *Node[T] {
+	if c == nil || c.owner == n.owner {
+		return c
+	}
+
+	cc := c.clone(n, n.owner)
+	if n.Left == c {
+		n.Left = cc
+	} else {
+		n.Right = cc
+	}
+
+	return cc
+}
+
+// mutFind finds node with value v in subtree n like Find, but shared nodes
+// on the path are copied by mut, so found node can be changed.
+func (n *Node[T]) mutFind(v T) *Node[T] {
+	for n != nil {
+		c := compare.Ordered(v, n.Value)
+		if c == 0 {
+			return n
+		} else if c > 0 {
+			n = n.mut(n.Right)
+		} else {
+			n = n.mut(n.Left)
+		}
+	}
+
+	return nil
+}
+
+// mutMin finds min node in subtree n copying shared nodes on the path, see mutFind.
+func (n *Node[T]) mutMin() *Node[T] {
+	for n.Left != nil {
+		n = n.mut(n.Left)
+	}
+
+	return n
+}
+
+// mutMax finds max node in subtree n copying shared nodes on the path, see mutFind.
+func (n *Node[T]) mutMax() *Node[T] {
+	for n.Right != nil {
+		n = n.mut(n.Right)
+	}
+
+	return n
+}
+
+// ownAll returns subtree n with parent p where all nodes are owned by o,
+// shared nodes are copied. Children of shared node are shared too.
+func (n *Node[T]) ownAll(p *Node[T], o *token) *Node[T] {
+	if n == nil {
+		return nil
+	}
+
+	if n.owner != o {
+		n = n.clone(p, o)
+	}
+
+	n.Left = n.Left.ownAll(n, o)
+	n.Right = n.Right.ownAll(n, o)
+
+	return n
+}
+
 // delete deletes node n from subtree n and then resore broken red-black properties.
-func (n *Node[T]) delete() *Node[T] {
+// If aug is true sizes and sums of hashes are updated on the path to the root.
+func (n *Node[T]) delete(aug bool) *Node[T] {
 	if n == nil {
 		panic("can not delete nil node")
 	}
 
+	// n is owned, nodes that are changed below it are copied if they are shared
 	var d *Node[T] // node that will be physically deleted
 	if n.Left == nil || n.Right == nil {
 		d = n
 	} else {
-		d = n.Successor()
+		d = n.mut(n.Right).mutMin()
 	}
 
 	var c *Node[T] // child node that will replace deleted
 	if d.Left != nil {
-		c = d.Left
+		c = d.mut(d.Left)
 	} else {
-		c = d.Right
+		c = d.mut(d.Right)
 	}
 
 	cfake := c == nil
@@ -160,6 +618,7 @@ func (n *Node[T]) delete() *Node[T] {
 		c = &Node[T]{
 			Red:    false,
 			Parent: d.Parent,
+			owner:  d.owner,
 		}
 	}
 
@@ -173,6 +632,15 @@ func (n *Node[T]) delete() *Node[T] {
 
 	if d != n {
 		n.Value = d.Value
+		n.hash = d.hash
+	}
+
+	// d is not in the tree anymore, hints to it must not be followed
+	d.Parent, d.Left, d.Right = nil, nil, nil
+
+	// d is removed so sizes and sums of hashes are changed up to the root
+	for p := c.Parent; aug && p != nil; p = p.Parent {
+		p.update()
 	}
 
 	pp := c
@@ -200,12 +668,13 @@ func (n *Node[T]) deleteFixup() *Node[T] {
 	for n.Parent != nil && n.Black() {
 		if n == n.Parent.Left {
 			// case 1 - transform it to case 2, 3 or 4
-			r := n.Parent.Right
+			// sibling is always changed, so it is copied if it is shared
+			r := n.Parent.mut(n.Parent.Right)
 			if r.Red {
 				r.Red = false
 				r.Parent.Red = true
 				n.Parent.RotateLeft()
-				r = n.Parent.Right
+				r = n.Parent.mut(n.Parent.Right)
 			}
 
 			if r.Right.Black() && r.Left.Black() {
@@ -216,7 +685,7 @@ func (n *Node[T]) deleteFixup() *Node[T] {
 				if r.Right.Black() {
 					// case 3: r.Right is black
 					// transform it to case 4
-					r.Left.Red = false
+					r.mut(r.Left).Red = false
 					r.Red = true
 					r.RotateRight()
 					r = n.Parent.Right
@@ -230,17 +699,17 @@ func (n *Node[T]) deleteFixup() *Node[T] {
 				// are restored
 				r.Red = n.Parent.Red
 				n.Parent.Red = false
-				r.Right.Red = false
+				r.mut(r.Right).Red = false
 				n.Parent.RotateLeft()
 				break
 			}
 		} else {
-			l := n.Parent.Left
+			l := n.Parent.mut(n.Parent.Left)
 			if l.Red {
 				l.Red = false
 				l.Parent.Red = true
 				n.Parent.RotateRight()
-				l = n.Parent.Left
+				l = n.Parent.mut(n.Parent.Left)
 			}
 
 			if l.Left.Black() && l.Right.Black() {
@@ -248,7 +717,7 @@ func (n *Node[T]) deleteFixup() *Node[T] {
 				n = n.Parent
 			} else {
 				if l.Left.Black() {
-					l.Right.Red = false
+					l.mut(l.Right).Red = false
 					l.Red = true
 					l.RotateLeft()
 					l = n.Parent.Left
@@ -256,7 +725,7 @@ func (n *Node[T]) deleteFixup() *Node[T] {
 
 				l.Red = n.Parent.Red
 				n.Parent.Red = false
-				l.Left.Red = false
+				l.mut(l.Left).Red = false
 				n.Parent.RotateRight()
 				break
 			}
@@ -268,37 +737,99 @@ func (n *Node[T]) deleteFixup() *Node[T] {
 }
 
 // insert inserts v to search tree and restore broken red-black properties.
-// insert returns node that can be new root, or it's parent can be new root.
-func (n *Node[T]) insert(v T) *Node[T] {
+// insert returns inserted node and node that can be new root, or it's parent
+// can be new root.
+func (n *Node[T]) insert(v T, h uint64, aug bool) (*Node[T], *Node[T]) {
 	if n == nil {
 		panic("can not insert into nil node")
 	}
 
+	// n is owned, shared nodes on the path are copied since they are changed
 	var p *Node[T]
 
 	for n != nil {
 		p = n
 
-		if v > p.Value {
-			n = n.Right
+		if compare.Ordered(v, p.Value) > 0 {
+			n = n.mut(n.Right)
 		} else {
-			n = n.Left
+			n = n.mut(n.Left)
 		}
 	}
 
+	return p.attach(v, h, aug, compare.Ordered(v, p.Value) > 0)
+}
+
+// insertNear inserts v to search tree starting search from owned node n instead
+// of root and restores broken red-black properties. If v goes next to n it is attached
+// in amortised O(1) comparisons, otherwise search goes up only to the lowest
+// ancestor which subtree has position of v. It returns the same nodes as insert.
+func (n *Node[T]) insertNear(v T, h uint64, aug bool) (*Node[T], *Node[T]) {
+	if n == nil {
+		panic("can not insert into nil node")
+	}
+
+	if compare.Ordered(v, n.Value) > 0 {
+		s := n.Successor()
+		if s == nil || compare.Ordered(v, s.Value) <= 0 {
+			// v goes between n and its successor
+			if n.Right == nil {
+				return n.attach(v, h, aug, true)
+			}
+
+			return n.mut(n.Right).mutMin().attach(v, h, aug, false)
+		}
+
+		// subtree of n is bounded above by parent of the first left subtree
+		for n.Parent != nil && !(n == n.Parent.Left && compare.Ordered(v, n.Parent.Value) <= 0) {
+			n = n.Parent
+		}
+	} else {
+		p := n.Predecessor()
+		if p == nil || compare.Ordered(v, p.Value) >= 0 {
+			// v goes between predecessor of n and n
+			if n.Left == nil {
+				return n.attach(v, h, aug, false)
+			}
+
+			return n.mut(n.Left).mutMax().attach(v, h, aug, true)
+		}
+
+		// subtree of n is bounded below by parent of the first right subtree
+		for n.Parent != nil && !(n == n.Parent.Right && compare.Ordered(v, n.Parent.Value) >= 0) {
+			n = n.Parent
+		}
+	}
+
+	return n.insert(v, h, aug)
+}
+
+// attach links new red node with value v as right or left child of n, that
+// child must be nil, and restores broken red-black properties. If aug is true
+// sizes and sums of hashes are updated on the path to the root.
+func (n *Node[T]) attach(v T, h uint64, aug, right bool) (*Node[T], *Node[T]) {
 	nn := &Node[T]{
 		Value:  v,
 		Red:    true,
-		Parent: p,
+		Parent: n,
+		owner:  n.owner,
+		hash:   h,
+		sum:    h,
+		size:   1,
 	}
 
-	if v > p.Value {
-		p.Right = nn
+	if right {
+		n.Right = nn
 	} else {
-		p.Left = nn
+		n.Left = nn
 	}
 
-	return nn.insertFixup()
+	for p := n; aug && p != nil; p = p.Parent {
+		p.sum += h
+		p.size++
+	}
+
+	return nn, nn.insertFixup()
 }
 
 // insertFixup restores red-black properties that could be broken after inserting red node.
@@ -317,6 +848,7 @@ func (n *Node[T]) insertFixup() *Node[T] {
 			// case 1: we got red uncle
 			// makes uncle and parent black
 			// and repaet fixup for grand parent
+			uncle = n.Parent.Parent.mut(uncle)
 			uncle.Red = false
 			n.Parent.Red = false
 			n.Parent.Parent.Red = true
@@ -426,6 +958,9 @@ func (n *Node[T]) RotateLeft() {
 
 	c.SetLeft(n)
 	n.SetRight(d)
+
+	n.update()
+	c.update()
 }
 
 // RotateRight makes right rotation for node n.
@@ -458,10 +993,38 @@ func (n *Node[T]) RotateRight() {
 
 	b.SetRight(n)
 	n.SetLeft(e)
+
+	n.update()
+	b.update()
+}
+
+// update recalculates augmented data of n from its children.
+func (n *Node[T]) update() {
+	n.sum = n.hash + n.Left.hashSum() + n.Right.hashSum()
+	n.size = 1 + n.Left.subtreeSize() + n.Right.subtreeSize()
+}
+
+// hashSum returns sum of hashes in subtree n.
+func (n *Node[T]) hashSum() uint64 {
+	if n == nil {
+		return 0
+	}
+
+	return n.sum
+}
+
+// subtreeSize returns number of nodes in subtree n.
+func (n *Node[T]) subtreeSize() int {
+	if n == nil {
+		return 0
+	}
+
+	return n.size
 }
 
 // ReplaceChild replaces left or right child old with new.
-// Old must be left or right child.
+// Old must be left or right child. Parent of new is not changed if n is
+// node of a copy made by Clone and new is shared with the cloned tree.
 func (n *Node[T]) ReplaceChild(old, new *Node[T]) {
 	if n == nil {
 		return
@@ -473,31 +1036,33 @@ func (n *Node[T]) ReplaceChild(old, new *Node[T]) {
 		n.Right = new
 	}
 
-	if new != nil {
+	if new != nil && n.owner.adopts(new.owner) {
 		new.Parent = n
 	}
 }
 
-// SetLeft sets  l as left child for n.
+// SetLeft sets  l as left child for n. Parent of l is not changed if n is
+// node of a copy made by Clone and l is shared with the cloned tree.
 func (n *Node[T]) SetLeft(l *Node[T]) {
 	if n == nil {
 		return
 	}
 
 	n.Left = l
-	if l != nil {
+	if l != nil && n.owner.adopts(l.owner) {
 		l.Parent = n
 	}
 }
 
-// SetRight sets r as right child for n.
+// SetRight sets r as right child for n. Parent of r is not changed if n is
+// node of a copy made by Clone and r is shared with the cloned tree.
 func (n *Node[T]) SetRight(r *Node[T]) {
 	if n == nil {
 		return
 	}
 
 	n.Right = r
-	if r != nil {
+	if r != nil && n.owner.adopts(r.owner) {
 		r.Parent = n
 	}
 }
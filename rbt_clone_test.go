@@ -0,0 +1,451 @@
+package rbt_test
+
+import (
+	"constraints"
+	"errors"
+	"fmt"
+	"math/bits"
+	"math/rand"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"gotest.com/rbt"
+	"gotest.com/rbt/compare"
+)
+
+func TestTreeClone(t *testing.T) {
+	tree := &rbt.Tree[int]{}
+	vs := []int{}
+
+	for i := 0; i < 100; i++ {
+		v := rand.Intn(64)
+		vs = append(vs, v)
+		tree.Insert(v)
+	}
+
+	c := tree.Clone()
+	if c.Root != tree.Root {
+		t.Fatal("clone copied nodes before write")
+	}
+
+	c.Insert(1000)
+	tree.Delete(vs[0])
+	tree.Insert(-1)
+
+	cvs := append([]int{1000}, vs...)
+	sort.Ints(cvs)
+
+	tvs := append([]int{-1}, vs[1:]...)
+	sort.Ints(tvs)
+
+	if got := copyValues(c); !reflect.DeepEqual(got, cvs) {
+		t.Fatalf("clone values %v, expected %v", got, cvs)
+	}
+
+	if got := treeValues(tree); !reflect.DeepEqual(got, tvs) {
+		t.Fatalf("tree values %v, expected %v", got, tvs)
+	}
+
+	if err := checkCloned(tree.Root); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := checkCopy(c.Root); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTreeCloneChain(t *testing.T) {
+	tree := &rbt.Tree[int]{}
+	trees := []*rbt.Tree[int]{}
+	expected := [][]int{}
+	vs := []int{}
+
+	for i := 0; i < 50; i++ {
+		v := rand.Intn(64)
+		if i%3 == 2 {
+			tree.Delete(vs[0])
+			vs = vs[1:]
+		} else {
+			tree.Insert(v)
+			vs = append(vs, v)
+		}
+
+		trees = append(trees, tree.Clone())
+		e := append([]int{}, vs...)
+		sort.Ints(e)
+		expected = append(expected, e)
+	}
+
+	for i, c := range trees {
+		if got := copyValues(c); !reflect.DeepEqual(got, expected[i]) {
+			t.Fatalf("clone %d values %v, expected %v", i, got, expected[i])
+		}
+
+		err := checkCopy(c.Root)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := treeValues(tree); !reflect.DeepEqual(got, expected[len(expected)-1]) {
+		t.Fatalf("tree values %v, expected %v", got, expected[len(expected)-1])
+	}
+
+	if err := checkCloned(tree.Root); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTreeCmpClone(t *testing.T) {
+	tree := &rbt.TreeCmp[int]{Cmp: compare.Ordered[int]}
+
+	for i := 0; i < 20; i++ {
+		tree.Insert(i)
+	}
+
+	c := tree.Clone()
+	for i := 0; i < 10; i++ {
+		c.Delete(i)
+	}
+
+	tree.Insert(100)
+
+	n := 0
+	for nd := tree.Root.Min(); nd != nil; nd = nd.Successor() {
+		n++
+	}
+
+	if n != 21 {
+		t.Fatal("unexpected tree size", n)
+	}
+
+	// Parent links of shared nodes of the copy lead to tree
+	n = 0
+	c.Ascend(func(v int) bool {
+		if v < 10 || v == 100 {
+			t.Fatal("unexpected value in clone", v)
+		}
+		n++
+		return true
+	})
+
+	if n != 10 {
+		t.Fatal("unexpected clone size", n)
+	}
+
+	if err := checkTreeCmp(tree.Root); err != nil {
+		t.Fatal(err)
+	}
+
+	if h := c.Height(); h > 2*bits.Len(uint(c.Len())) {
+		t.Fatal("clone is not balanced", h)
+	}
+}
+
+func TestTreeClonePathCopy(t *testing.T) {
+	tree := &rbt.Tree[int]{}
+	for i := 0; i < 1024; i++ {
+		tree.Insert(i * 2)
+	}
+
+	// delete of missing value copies nothing
+	c := tree.Clone()
+	if c.Delete(1) || c.Root != tree.Root {
+		t.Fatal("nodes are copied without change")
+	}
+
+	c.Insert(1)
+	c.Delete(100)
+
+	copied := c.Len() - sharedNodes(tree.Root, c.Root)
+	if copied > 4*tree.Height() {
+		t.Fatal("too many nodes are copied", copied)
+	}
+
+	vs := treeValues(tree)
+	if len(vs) != 1024 || vs[0] != 0 || vs[50] != 100 {
+		t.Fatal("tree is changed by clone")
+	}
+}
+
+func TestTreeCloneRandom(t *testing.T) {
+	trees := []*rbt.Tree[int]{{}}
+	expected := [][]int{{}}
+
+	for i := 0; i < 3000; i++ {
+		j := rand.Intn(len(trees))
+		if rand.Intn(20) == 0 {
+			trees = append(trees, trees[j].Clone())
+			expected = append(expected, append([]int{}, expected[j]...))
+			continue
+		}
+
+		v := rand.Intn(200)
+		if rand.Intn(3) == 0 {
+			k := sort.SearchInts(expected[j], v)
+			found := k < len(expected[j]) && expected[j][k] == v
+			if trees[j].Delete(v) != found {
+				t.Fatal("unexpected result of delete", v)
+			}
+
+			if found {
+				expected[j] = append(expected[j][:k], expected[j][k+1:]...)
+			}
+		} else if rand.Intn(2) == 0 {
+			trees[j].Insert(v)
+			expected[j] = append(expected[j], v)
+			sort.Ints(expected[j])
+		} else if v, ok := trees[j].PopMin(); ok {
+			if v != expected[j][0] {
+				t.Fatal("unexpected min", v, expected[j][0])
+			}
+
+			expected[j] = expected[j][1:]
+		}
+	}
+
+	// only the first tree is not a copy, its Parent links are valid
+	if got := treeValues(trees[0]); !reflect.DeepEqual(got, expected[0]) {
+		t.Fatalf("tree values %v, expected %v", got, expected[0])
+	}
+
+	if err := checkCloned(trees[0].Root); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tree := range trees {
+		if got := copyValues(tree); !reflect.DeepEqual(got, expected[i]) {
+			t.Fatalf("tree %d values %v, expected %v", i, got, expected[i])
+		}
+
+		if err := checkCopy(tree.Root); err != nil {
+			t.Fatal(err)
+		}
+
+		if min, ok := tree.Min(); ok && min != expected[i][0] {
+			t.Fatal("unexpected min", min)
+		}
+
+		if max, ok := tree.Max(); ok && max != expected[i][len(expected[i])-1] {
+			t.Fatal("unexpected max", max)
+		}
+	}
+}
+
+func TestTreeCloneParents(t *testing.T) {
+	tree := &rbt.Tree[int]{}
+	copies := []*rbt.Tree[int]{}
+	vs := []int{}
+
+	for i := 0; i < 2000; i++ {
+		if i%100 == 0 {
+			copies = append(copies, tree.Clone())
+		}
+
+		v := rand.Intn(500)
+		if i%3 == 2 {
+			if tree.Delete(v) {
+				k := sort.SearchInts(vs, v)
+				vs = append(vs[:k], vs[k+1:]...)
+			}
+		} else {
+			tree.Insert(v)
+			k := sort.SearchInts(vs, v)
+			vs = append(vs[:k], append([]int{v}, vs[k:]...)...)
+		}
+
+		// copies are changed too, tree must not see it
+		c := copies[rand.Intn(len(copies))]
+		c.Insert(v + 1000)
+		c.Delete(rand.Intn(500))
+
+		if i%50 == 0 {
+			if err := checkCloned(tree.Root); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if got := treeValues(tree); !reflect.DeepEqual(got, vs) {
+		t.Fatalf("tree values %v, expected %v", got, vs)
+	}
+
+	back := []int{}
+	for n := tree.Root.Max(); n != nil; n = n.Predecessor() {
+		back = append([]int{n.Value}, back...)
+	}
+
+	if !reflect.DeepEqual(back, vs) {
+		t.Fatalf("values in reverse order %v, expected %v", back, vs)
+	}
+
+	for _, c := range copies {
+		if err := checkCopy(c.Root); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTreeCloneConcurrent(t *testing.T) {
+	tree := &rbt.Tree[int]{}
+	for i := 0; i < 1000; i++ {
+		tree.Insert(i)
+	}
+
+	// Clone does not change tree, so it runs together with readers
+	var wg sync.WaitGroup
+	copies := make([]*rbt.Tree[int], 4)
+	for i := range copies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			copies[i] = tree.Clone()
+			if !tree.Contains(500) || tree.Len() != 1000 {
+				t.Error("unexpected tree")
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	// tree changes Parent links of shared nodes, readers of copies
+	// do not follow them
+	for _, c := range copies {
+		wg.Add(1)
+		go func(c *rbt.Tree[int]) {
+			defer wg.Done()
+
+			if got := copyValues(c); len(got) != 1000 || !c.Contains(999) {
+				t.Error("unexpected copy", len(got))
+			}
+		}(c)
+	}
+
+	for i := 0; i < 1000; i += 2 {
+		tree.Delete(i)
+	}
+
+	wg.Wait()
+
+	if err := checkCloned(tree.Root); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := treeValues(tree); len(got) != 500 || got[0] != 1 {
+		t.Fatal("unexpected tree values", len(got))
+	}
+}
+
+// copyValues returns all values of copy made by Clone in order, Parent
+// links of its nodes can lead to the cloned tree, so Successor is not used.
+func copyValues[T constraints.Ordered](tree *rbt.Tree[T]) []T {
+	vs := []T{}
+	tree.Ascend(func(v T) bool {
+		vs = append(vs, v)
+		return true
+	})
+
+	return vs
+}
+
+// checkCopy checks red-black properties of copy made by Clone like
+// checkTree, but Parent links are not checked.
+func checkCopy[T constraints.Ordered](n *rbt.Node[T]) error {
+	if n == nil {
+		return nil
+	}
+
+	if n.Red {
+		return errors.New("root not black")
+	}
+
+	var red func(n *rbt.Node[T]) error
+	red = func(n *rbt.Node[T]) error {
+		if n == nil {
+			return nil
+		}
+
+		if n.Red && (!n.Left.Black() || !n.Right.Black()) {
+			return fmt.Errorf("red node %v has red child", n.Value)
+		}
+
+		if err := red(n.Left); err != nil {
+			return err
+		}
+
+		return red(n.Right)
+	}
+
+	if err := red(n); err != nil {
+		return err
+	}
+
+	_, err := blackHeight(n)
+	return err
+}
+
+// checkCloned checks that tree n is valid and every child has its node as
+// Parent, so Successor and Predecessor work after Clone.
+func checkCloned[T constraints.Ordered](n *rbt.Node[T]) error {
+	if n != nil && n.Parent != nil {
+		return errors.New("root has parent")
+	}
+
+	var parents func(n *rbt.Node[T]) error
+	parents = func(n *rbt.Node[T]) error {
+		for _, c := range []*rbt.Node[T]{n.Left, n.Right} {
+			if c == nil {
+				continue
+			}
+
+			if c.Parent != n {
+				return fmt.Errorf("wrong parent of %v", c.Value)
+			}
+
+			if err := parents(c); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if n != nil {
+		if err := parents(n); err != nil {
+			return err
+		}
+	}
+
+	return checkTree(n)
+}
+
+// sharedNodes returns number of nodes of subtree b that are in subtree a.
+func sharedNodes[T constraints.Ordered](a, b *rbt.Node[T]) int {
+	ns := map[*rbt.Node[T]]bool{}
+
+	var walk func(n *rbt.Node[T], fn func(n *rbt.Node[T]))
+	walk = func(n *rbt.Node[T], fn func(n *rbt.Node[T])) {
+		if n != nil {
+			fn(n)
+			walk(n.Left, fn)
+			walk(n.Right, fn)
+		}
+	}
+
+	walk(a, func(n *rbt.Node[T]) {
+		ns[n] = true
+	})
+
+	c := 0
+	walk(b, func(n *rbt.Node[T]) {
+		if ns[n] {
+			c++
+		}
+	})
+
+	return c
+}
@@ -7,18 +7,67 @@ import (
 // TreeCmp represents red-black tree with more flexible approach using Cmp function.
 type TreeCmp[T any] struct {
 	Root *NodeCmp[T]
-	Cmp func(a, b T) int
+	Cmp  func(a, b T) int
+	// Debug enables checking of Cmp on every insert, see Err.
+	Debug bool
+
+	owner *token
+	hash  func(v T) uint64
+	aug   bool // sizes and hashes of subtrees are kept, see EnableSubtreeSize
+	count int
+	min   *NodeCmp[T] // cached most left node, see PeekMin
+	max   *NodeCmp[T] // cached most right node, see PeekMax
+	err   error       // first inconsistency of Cmp, see Err
+}
+
+// NewTreeCmp returns empty tree ordered by cmp. Comparators for struct
+// values can be built with package compare.
+func NewTreeCmp[T any](cmp func(a, b T) int) *TreeCmp[T] {
+	if cmp == nil {
+		panic(ErrNilCmp)
+	}
+
+	return &TreeCmp[T]{Cmp: cmp}
 }
 
 func (t *TreeCmp[T]) Insert(v T) {
+	t.insertNear(nil, v)
+}
+
+// insertNear inserts v starting search from node f of t, or from root if f
+// is nil, and returns node of v.
+func (t *TreeCmp[T]) insertNear(f *NodeCmp[T], v T) *NodeCmp[T] {
+	cmp := t.cmp()
+	h := t.hashOf(v)
+	t.count++
+	t.own()
+
 	if t.Root == nil {
 		t.Root = &NodeCmp[T]{
 			Value: v,
+			owner: t.owner,
+			hash:  h,
+			sum:   h,
+			size:  1,
 		}
-		return
+		t.min, t.max = t.Root, t.Root
+		return t.Root
 	}
 
-	top := t.Root.insert(v, t.Cmp)
+	// f can be changed only if it is attached to t, otherwise search starts from root
+	var nn, top *NodeCmp[T]
+	if !t.attached(f) {
+		nn, top = t.Root.insert(v, h, t.aug, cmp)
+	} else {
+		nn, top = f.insertNear(v, h, t.aug, cmp)
+	}
+
+	// new min and max are attached to the old ones and stay their children
+	if t.min.Left == nn {
+		t.min = nn
+	} else if t.max.Right == nn {
+		t.max = nn
+	}
 
 	// insert can replace root - so check it
 	if top.Parent == nil {
@@ -26,26 +75,248 @@ func (t *TreeCmp[T]) Insert(v T) {
 	} else if top.Parent.Parent == nil {
 		t.Root = top.Parent
 	}
+
+	// checkOrder walks t from root, so root must be set already
+	if t.Debug {
+		t.checkOrder(nn)
+	}
+
+	return nn
 }
 
 func (t *TreeCmp[T]) Delete(v T) bool {
-	n := t.Root.Find(v, t.Cmp)
+	n := t.Root.Find(v, t.cmp())
 	if n == nil {
 		return false
 	}
 
-	c := n.delete()
+	t.deleteNode(t.mutNode(n))
+
+	return true
+}
+
+// PeekMin returns min value in t in O(1) or false if t is empty.
+func (t *TreeCmp[T]) PeekMin() (T, bool) {
+	return t.Min()
+}
+
+// PeekMax returns max value in t in O(1) or false if t is empty.
+func (t *TreeCmp[T]) PeekMax() (T, bool) {
+	return t.Max()
+}
+
+// PopMin deletes min value from t and returns it or false if t is empty.
+// Rebalancing after removal is amortised O(1).
+func (t *TreeCmp[T]) PopMin() (T, bool) {
+	t.own()
 
-	// delete can replace root - so check it
-	if c == nil || c.Parent == nil {
-		t.Root = c
-	} else if c.Parent.Parent == nil {
-		t.Root = c.Parent
-	} else if c.Parent.Parent.Parent == nil {
-		t.Root = c.Parent.Parent
+	n := t.min
+	if n == nil {
+		var zero T
+		return zero, false
 	}
 
-	return true
+	v := n.Value
+	t.deleteNode(n)
+
+	return v, true
+}
+
+// PopMax deletes max value from t and returns it or false if t is empty.
+// Rebalancing after removal is amortised O(1).
+func (t *TreeCmp[T]) PopMax() (T, bool) {
+	t.own()
+
+	n := t.max
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+
+	v := n.Value
+	t.deleteNode(n)
+
+	return v, true
+}
+
+// deleteNode deletes n from t and keeps cached min and max nodes. n must
+// be owned by t, see mutNode.
+func (t *TreeCmp[T]) deleteNode(n *NodeCmp[T]) {
+	t.count--
+
+	// min and max have at most one child, so they are removed physically
+	// and their neighbours are found in O(1). The child is copied before
+	// it is cached if it is shared, because delete changes its parent.
+	// Node with two children is replaced by value of its successor that can be max.
+	min, max := t.min, t.max
+	if n == min {
+		n.mut(n.Right)
+		min = n.Successor()
+	}
+
+	if n == max {
+		n.mut(n.Left)
+		max = n.Predecessor()
+	} else if n.Left != nil && n.Right != nil && n.Successor() == max {
+		max = n
+	}
+
+	c := n.delete(t.aug)
+
+	// delete can replace root and returned node can be deep
+	// in the tree (or be already removed) - so go up to the root
+	for c != nil && c.Parent != nil {
+		c = c.Parent
+	}
+	t.Root = c
+	t.min, t.max = min, max
+}
+
+// Clone returns a copy of t in O(1). Nodes stay shared by t and the copy
+// and are copied on write, Parent links are valid only in t, see Tree.Clone.
+func (t *TreeCmp[T]) Clone() *TreeCmp[T] {
+	c := *t
+	c.owner = &token{copy: true}
+	t.owner.share()
+
+	return &c
+}
+
+// attached returns true if n is node of t that can be changed and followed
+// by Parent links, see Tree.attached.
+func (t *TreeCmp[T]) attached(n *NodeCmp[T]) bool {
+	return n != nil && t.owner != nil && n.owner == t.owner && (n.Parent != nil || n == t.Root)
+}
+
+// own gives t new token on the first write or after Clone and makes root
+// of t owned by t, copying it if it is shared with a clone.
+// Paths to min and max are copied too, so cached min and max nodes are
+// owned and are never replaced by copies.
+func (t *TreeCmp[T]) own() {
+	t.owner = t.owner.next()
+	if t.Root == nil || t.Root.owner == t.owner {
+		return
+	}
+
+	t.Root = t.Root.clone(nil, t.owner)
+	t.min, t.max = t.Root.mutMin(), t.Root.mutMax()
+}
+
+// mutNode returns node with value of n that can be changed. If n is shared
+// with a clone, it is found again from root copying nodes on the path.
+func (t *TreeCmp[T]) mutNode(n *NodeCmp[T]) *NodeCmp[T] {
+	t.own()
+	if n.owner == t.owner {
+		return n
+	}
+
+	return t.Root.mutFind(n.Value, t.cmp())
+}
+
+// ownAll makes all nodes of t owned by t, copying nodes that are shared with a clone.
+func (t *TreeCmp[T]) ownAll() {
+	t.own()
+	t.Root = t.Root.ownAll(nil, t.owner)
+	t.min, t.max = t.Root.Min(), t.Root.Max()
+}
+
+// Contains returns true if t contains value v.
+func (t *TreeCmp[T]) Contains(v T) bool {
+	return t.Root.Find(v, t.cmp()) != nil
+}
+
+// Find returns value of t that is equal to v or false if there is no such value.
+func (t *TreeCmp[T]) Find(v T) (T, bool) {
+	n := t.Root.Find(v, t.cmp())
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+
+	return n.Value, true
+}
+
+// Min returns min value in t or false if t is empty.
+func (t *TreeCmp[T]) Min() (T, bool) {
+	n := t.min
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+
+	return n.Value, true
+}
+
+// Max returns max value in t or false if t is empty.
+func (t *TreeCmp[T]) Max() (T, bool) {
+	n := t.max
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+
+	return n.Value, true
+}
+
+// Len returns number of values in t.
+func (t *TreeCmp[T]) Len() int {
+	return t.count
+}
+
+// EnableSubtreeSize turns on counting of nodes in subtrees of t, so
+// CountPrefixBytes takes O(log n) and CountLess can be used on root of t.
+// Sizes are updated on the path to the root by every insert and delete,
+// so they are not kept by default. EnableSubtreeSize takes O(n).
+func (t *TreeCmp[T]) EnableSubtreeSize() {
+	t.ownAll()
+	t.aug = true
+	t.Root.rehash(t.hashOf)
+}
+
+// countRange returns number of values of t in range r in O(log n) if sizes
+// of subtrees are kept, or in O(log n + k) for k values in r otherwise.
+func (t *TreeCmp[T]) countRange(r KeyRange[T]) int {
+	cmp := t.cmp()
+	if !t.aug {
+		var it iterCmp[T]
+		n := it.min(t.Root)
+		if !r.LoInf {
+			n = it.lowerBound(t.Root, r.Lo, cmp)
+		}
+
+		c := 0
+		for ; n != nil && (r.HiInf || cmp(n.Value, r.Hi) < 0); n = it.next() {
+			c++
+		}
+
+		return c
+	}
+
+	c := t.count
+	if !r.HiInf {
+		c = t.Root.CountLess(r.Hi, cmp)
+	}
+
+	if !r.LoInf {
+		c -= t.Root.CountLess(r.Lo, cmp)
+	}
+
+	if c < 0 {
+		// lo is greater than hi
+		return 0
+	}
+
+	return c
+}
+
+// Ascend calls fn for values of t in ascending order until fn returns false.
+func (t *TreeCmp[T]) Ascend(fn func(v T) bool) {
+	var it iterCmp[T]
+	for n := it.min(t.Root); n != nil; n = it.next() {
+		if !fn(n.Value) {
+			return
+		}
+	}
 }
 
 func (t *TreeCmp[T]) Height() int {
@@ -62,6 +333,10 @@ type NodeCmp[T any] struct {
 	Parent *NodeCmp[T]
 	Red    bool
 	Value  T
+	owner  *token
+	hash   uint64 // hash of Value, see EnableSubtreeHash
+	sum    uint64 // sum of hashes in subtree n
+	size   int    // number of nodes in subtree n, see EnableSubtreeSize
 }
 
 // Black returns true if node is black. Nil node is considered black.
@@ -88,7 +363,40 @@ func (n *NodeCmp[T]) Find(v T, cmp func(a, b T) int) *NodeCmp[T] {
 	return n
 }
 
-// Finds node Successor or nil if there is no successor.
+// LowerBound finds first node with value that is not less than v in subtree n.
+func (n *NodeCmp[T]) LowerBound(v T, cmp func(a, b T) int) *NodeCmp[T] {
+	var lb *NodeCmp[T]
+	for n != nil {
+		if cmp(n.Value, v) < 0 {
+			n = n.Right
+		} else {
+			lb = n
+			n = n.Left
+		}
+	}
+
+	return lb
+}
+
+// CountLess returns number of values less than v in subtree n in O(log n).
+// Sizes of subtrees are kept only in Seq and in trees with EnableSubtreeSize
+// or EnableSubtreeHash.
+func (n *NodeCmp[T]) CountLess(v T, cmp func(a, b T) int) int {
+	c := 0
+	for n != nil {
+		if cmp(n.Value, v) < 0 {
+			c += n.Left.subtreeSize() + 1
+			n = n.Right
+		} else {
+			n = n.Left
+		}
+	}
+
+	return c
+}
+
+// Finds node Successor or nil if there is no successor. Successor follows
+// Parent links, so it must not be used in cloned trees, see Tree.Clone.
 func (n *NodeCmp[T]) Successor() *NodeCmp[T] {
 	if n == nil {
 		return nil
@@ -107,6 +415,26 @@ func (n *NodeCmp[T]) Successor() *NodeCmp[T] {
 	return p
 }
 
+// Predecessor finds node predecessor or nil if there is no predecessor.
+// Like Successor, it must not be used in cloned trees.
+func (n *NodeCmp[T]) Predecessor() *NodeCmp[T] {
+	if n == nil {
+		return nil
+	}
+
+	if n.Left != nil {
+		return n.Left.Max()
+	}
+
+	p := n.Parent
+	for p != nil && n == p.Left {
+		n = p
+		p = p.Parent
+	}
+
+	return p
+}
+
 // Finds min (most left) value in tree. Or nil if tree is empty.
 func (n *NodeCmp[T]) Min() *NodeCmp[T] {
 	if n == nil {
@@ -133,24 +461,123 @@ func (n *NodeCmp[T]) Max() *NodeCmp[T] {
 	return n
 }
 
+// clone returns copy of n with parent p that is owned by o, children of
+// n are shared by n and the copy.
+func (n *NodeCmp[T]) clone(p *NodeCmp[T], o *token) *NodeCmp[T] {
+	c := &NodeCmp[T]{
+		Left:   n.Left,
+		Right:  n.Right,
+		Parent: p,
+		Red:    n.Red,
+		Value:  n.Value,
+		owner:  o,
+		hash:   n.hash,
+		sum:    n.sum,
+		size:   n.size,
+	}
+
+	// children stay shared, Parent links of them lead to the copy if it
+	// is owned by tree that keeps them valid, see token.adopts
+	if c.Left != nil && o.adopts(c.Left.owner) {
+		c.Left.Parent = c
+	}
+
+	if c.Right != nil && o.adopts(c.Right.owner) {
+		c.Right.Parent = c
+	}
+
+	return c
+}
+
+// mut returns child c of n that can be changed. If c is shared with
+// a clone, it is replaced by its copy owned by owner of n, so n must be
+// owned by tree that is changed.
+func (n *NodeCmp[T]) mut(c *NodeCmp[T]) *NodeCmp[T] {
+	if c == nil || c.owner == n.owner {
+		return c
+	}
+
+	cc := c.clone(n, n.owner)
+	if n.Left == c {
+		n.Left = cc
+	} else {
+		n.Right = cc
+	}
+
+	return cc
+}
+
+// mutFind finds node with value v in subtree n like Find, but shared nodes
+// on the path are copied by mut, so found node can be changed.
+func (n *NodeCmp[T]) mutFind(v T, cmp func(a, b T) int) *NodeCmp[T] {
+	for n != nil {
+		if cmp(n.Value, v) == 0 {
+			return n
+		} else if cmp(v, n.Value) > 0 {
+			n = n.mut(n.Right)
+		} else {
+			n = n.mut(n.Left)
+		}
+	}
+
+	return nil
+}
+
+// mutMin finds min node in subtree n copying shared nodes on the path, see mutFind.
+func (n *NodeCmp[T]) mutMin() *NodeCmp[T] {
+	for n.Left != nil {
+		n = n.mut(n.Left)
+	}
+
+	return n
+}
+
+// mutMax finds max node in subtree n copying shared nodes on the path, see mutFind.
+func (n *NodeCmp[T]) mutMax() *NodeCmp[T] {
+	for n.Right != nil {
+		n = n.mut(n.Right)
+	}
+
+	return n
+}
+
+// ownAll returns subtree n with parent p where all nodes are owned by o,
+// shared nodes are copied. Children of shared node are shared too.
+func (n *NodeCmp[T]) ownAll(p *NodeCmp[T], o *token) *NodeCmp[T] {
+	if n == nil {
+		return nil
+	}
+
+	if n.owner != o {
+		n = n.clone(p, o)
+	}
+
+	n.Left = n.Left.ownAll(n, o)
+	n.Right = n.Right.ownAll(n, o)
+
+	return n
+}
+
 // delete deletes node n from subtree n and then resore broken red-black properties.
-func (n *NodeCmp[T]) delete() *NodeCmp[T] {
+// If aug is true sizes and sums of hashes are updated on the path to the root.
+func (n *NodeCmp[T]) delete(aug bool) *NodeCmp[T] {
 	if n == nil {
 		panic("can not delete nil node")
 	}
 
+	// n is owned, nodes that are changed below it are copied if they are shared
 	var d *NodeCmp[T] // node that will be physically deleted
 	if n.Left == nil || n.Right == nil {
 		d = n
 	} else {
-		d = n.Successor()
+		d = n.mut(n.Right).mutMin()
 	}
 
 	var c *NodeCmp[T] // child node that will replace deleted
 	if d.Left != nil {
-		c = d.Left
+		c = d.mut(d.Left)
 	} else {
-		c = d.Right
+		c = d.mut(d.Right)
 	}
 
 	cfake := c == nil
@@ -160,6 +587,7 @@ func (n *NodeCmp[T]) delete() *NodeCmp[T] {
 		c = &NodeCmp[T]{
 			Red:    false,
 			Parent: d.Parent,
+			owner:  d.owner,
 		}
 	}
 
@@ -173,6 +601,15 @@ func (n *NodeCmp[T]) delete() *NodeCmp[T] {
 
 	if d != n {
 		n.Value = d.Value
+		n.hash = d.hash
+	}
+
+	// d is not in the tree anymore, hints to it must not be followed
+	d.Parent, d.Left, d.Right = nil, nil, nil
+
+	// d is removed so sizes and sums of hashes are changed up to the root
+	for p := c.Parent; aug && p != nil; p = p.Parent {
+		p.update()
 	}
 
 	pp := c
@@ -200,12 +637,13 @@ func (n *NodeCmp[T]) deleteFixup() *NodeCmp[T] {
 	for n.Parent != nil && n.Black() {
 		if n == n.Parent.Left {
 			// case 1 - transform it to case 2, 3 or 4
-			r := n.Parent.Right
+			// sibling is always changed, so it is copied if it is shared
+			r := n.Parent.mut(n.Parent.Right)
 			if r.Red {
 				r.Red = false
 				r.Parent.Red = true
 				n.Parent.RotateLeft()
-				r = n.Parent.Right
+				r = n.Parent.mut(n.Parent.Right)
 			}
 
 			if r.Right.Black() && r.Left.Black() {
@@ -216,7 +654,7 @@ func (n *NodeCmp[T]) deleteFixup() *NodeCmp[T] {
 				if r.Right.Black() {
 					// case 3: r.Right is black
 					// transform it to case 4
-					r.Left.Red = false
+					r.mut(r.Left).Red = false
 					r.Red = true
 					r.RotateRight()
 					r = n.Parent.Right
@@ -230,17 +668,17 @@ func (n *NodeCmp[T]) deleteFixup() *NodeCmp[T] {
 				// are restored
 				r.Red = n.Parent.Red
 				n.Parent.Red = false
-				r.Right.Red = false
+				r.mut(r.Right).Red = false
 				n.Parent.RotateLeft()
 				break
 			}
 		} else {
-			l := n.Parent.Left
+			l := n.Parent.mut(n.Parent.Left)
 			if l.Red {
 				l.Red = false
 				l.Parent.Red = true
 				n.Parent.RotateRight()
-				l = n.Parent.Left
+				l = n.Parent.mut(n.Parent.Left)
 			}
 
 			if l.Left.Black() && l.Right.Black() {
@@ -248,7 +686,7 @@ func (n *NodeCmp[T]) deleteFixup() *NodeCmp[T] {
 				n = n.Parent
 			} else {
 				if l.Left.Black() {
-					l.Right.Red = false
+					l.mut(l.Right).Red = false
 					l.Red = true
 					l.RotateLeft()
 					l = n.Parent.Left
@@ -256,7 +694,7 @@ func (n *NodeCmp[T]) deleteFixup() *NodeCmp[T] {
 
 				l.Red = n.Parent.Red
 				n.Parent.Red = false
-				l.Left.Red = false
+				l.mut(l.Left).Red = false
 				n.Parent.RotateRight()
 				break
 			}
@@ -268,37 +706,99 @@ func (n *NodeCmp[T]) deleteFixup() *NodeCmp[T] {
 }
 
 // insert inserts v to search tree and restore broken red-black properties.
-// insert returns node that can be new root, or it's parent can be new root.
-func (n *NodeCmp[T]) insert(v T, cmp func(a, b T) int) *NodeCmp[T] {
+// insert returns inserted node and node that can be new root, or it's parent
+// can be new root.
+func (n *NodeCmp[T]) insert(v T, h uint64, aug bool, cmp func(a, b T) int) (*NodeCmp[T], *NodeCmp[T]) {
 	if n == nil {
 		panic("can not insert into nil node")
 	}
 
+	// n is owned, shared nodes on the path are copied since they are changed
 	var p *NodeCmp[T]
 
 	for n != nil {
 		p = n
 
 		if cmp(v, p.Value) > 0 {
-			n = n.Right
+			n = n.mut(n.Right)
 		} else {
-			n = n.Left
+			n = n.mut(n.Left)
 		}
 	}
 
+	return p.attach(v, h, aug, cmp(v, p.Value) > 0)
+}
+
+// insertNear inserts v to search tree starting search from owned node n instead
+// of root and restores broken red-black properties. If v goes next to n it is attached
+// in amortised O(1) comparisons, otherwise search goes up only to the lowest
+// ancestor which subtree has position of v. It returns the same nodes as insert.
+func (n *NodeCmp[T]) insertNear(v T, h uint64, aug bool, cmp func(a, b T) int) (*NodeCmp[T], *NodeCmp[T]) {
+	if n == nil {
+		panic("can not insert into nil node")
+	}
+
+	if cmp(v, n.Value) > 0 {
+		s := n.Successor()
+		if s == nil || cmp(v, s.Value) <= 0 {
+			// v goes between n and its successor
+			if n.Right == nil {
+				return n.attach(v, h, aug, true)
+			}
+
+			return n.mut(n.Right).mutMin().attach(v, h, aug, false)
+		}
+
+		// subtree of n is bounded above by parent of the first left subtree
+		for n.Parent != nil && !(n == n.Parent.Left && cmp(v, n.Parent.Value) <= 0) {
+			n = n.Parent
+		}
+	} else {
+		p := n.Predecessor()
+		if p == nil || cmp(v, p.Value) >= 0 {
+			// v goes between predecessor of n and n
+			if n.Left == nil {
+				return n.attach(v, h, aug, false)
+			}
+
+			return n.mut(n.Left).mutMax().attach(v, h, aug, true)
+		}
+
+		// subtree of n is bounded below by parent of the first right subtree
+		for n.Parent != nil && !(n == n.Parent.Right && cmp(v, n.Parent.Value) >= 0) {
+			n = n.Parent
+		}
+	}
+
+	return n.insert(v, h, aug, cmp)
+}
+
+// attach links new red node with value v as right or left child of n, that
+// child must be nil, and restores broken red-black properties. If aug is true
+// sizes and sums of hashes are updated on the path to the root.
+func (n *NodeCmp[T]) attach(v T, h uint64, aug, right bool) (*NodeCmp[T], *NodeCmp[T]) {
 	nn := &NodeCmp[T]{
 		Value:  v,
 		Red:    true,
-		Parent: p,
+		Parent: n,
+		owner:  n.owner,
+		hash:   h,
+		sum:    h,
+		size:   1,
 	}
 
-	if cmp(v, p.Value) > 0 {
-		p.Right = nn
+	if right {
+		n.Right = nn
 	} else {
-		p.Left = nn
+		n.Left = nn
+	}
+
+	for p := n; aug && p != nil; p = p.Parent {
+		p.sum += h
+		p.size++
 	}
 
-	return nn.insertFixup()
+	return nn, nn.insertFixup()
 }
 
 // insertFixup restores red-black properties that could be broken after inserting red node.
@@ -317,6 +817,7 @@ func (n *NodeCmp[T]) insertFixup() *NodeCmp[T] {
 			// case 1: we got red uncle
 			// makes uncle and parent black
 			// and repaet fixup for grand parent
+			uncle = n.Parent.Parent.mut(uncle)
 			uncle.Red = false
 			n.Parent.Red = false
 			n.Parent.Parent.Red = true
@@ -426,6 +927,9 @@ func (n *NodeCmp[T]) RotateLeft() {
 
 	c.SetLeft(n)
 	n.SetRight(d)
+
+	n.update()
+	c.update()
 }
 
 // RotateRight makes right rotation for node n.
@@ -458,10 +962,38 @@ func (n *NodeCmp[T]) RotateRight() {
 
 	b.SetRight(n)
 	n.SetLeft(e)
+
+	n.update()
+	b.update()
+}
+
+// update recalculates augmented data of n from its children.
+func (n *NodeCmp[T]) update() {
+	n.sum = n.hash + n.Left.hashSum() + n.Right.hashSum()
+	n.size = 1 + n.Left.subtreeSize() + n.Right.subtreeSize()
+}
+
+// hashSum returns sum of hashes in subtree n.
+func (n *NodeCmp[T]) hashSum() uint64 {
+	if n == nil {
+		return 0
+	}
+
+	return n.sum
+}
+
+// subtreeSize returns number of nodes in subtree n.
+func (n *NodeCmp[T]) subtreeSize() int {
+	if n == nil {
+		return 0
+	}
+
+	return n.size
 }
 
 // ReplaceChild replaces left or right child old with new.
-// Old must be left or right child.
+// Old must be left or right child. Parent of new is not changed if n is
+// node of a copy made by Clone and new is shared with the cloned tree.
 func (n *NodeCmp[T]) ReplaceChild(old, new *NodeCmp[T]) {
 	if n == nil {
 		return
@@ -473,31 +1005,33 @@ func (n *NodeCmp[T]) ReplaceChild(old, new *NodeCmp[T]) {
 		n.Right = new
 	}
 
-	if new != nil {
+	if new != nil && n.owner.adopts(new.owner) {
 		new.Parent = n
 	}
 }
 
-// SetLeft sets  l as left child for n.
+// SetLeft sets  l as left child for n. Parent of l is not changed if n is
+// node of a copy made by Clone and l is shared with the cloned tree.
 func (n *NodeCmp[T]) SetLeft(l *NodeCmp[T]) {
 	if n == nil {
 		return
 	}
 
 	n.Left = l
-	if l != nil {
+	if l != nil && n.owner.adopts(l.owner) {
 		l.Parent = n
 	}
 }
 
-// SetRight sets r as right child for n.
+// SetRight sets r as right child for n. Parent of r is not changed if n is
+// node of a copy made by Clone and r is shared with the cloned tree.
 func (n *NodeCmp[T]) SetRight(r *NodeCmp[T]) {
 	if n == nil {
 		return
 	}
 
 	n.Right = r
-	if r != nil {
+	if r != nil && n.owner.adopts(r.owner) {
 		r.Parent = n
 	}
 }
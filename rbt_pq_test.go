@@ -0,0 +1,127 @@
+package rbt_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"gotest.com/rbt"
+	"gotest.com/rbt/compare"
+)
+
+func TestTreePopMinMax(t *testing.T) {
+	tree := &rbt.Tree[int]{}
+	vs := []int{}
+
+	for i := 0; i < 1000; i++ {
+		// mix of inserts, deletes and pops with many duplicates
+		v := rand.Intn(100)
+		switch rand.Intn(5) {
+		case 0:
+			if tree.Delete(v) {
+				i := sort.SearchInts(vs, v)
+				vs = append(vs[:i], vs[i+1:]...)
+			}
+		case 1:
+			m, ok := tree.PopMin()
+			if ok != (len(vs) > 0) || ok && m != vs[0] {
+				t.Fatal("unexpected min", m, ok)
+			}
+
+			if ok {
+				vs = vs[1:]
+			}
+		case 2:
+			m, ok := tree.PopMax()
+			if ok != (len(vs) > 0) || ok && m != vs[len(vs)-1] {
+				t.Fatal("unexpected max", m, ok)
+			}
+
+			if ok {
+				vs = vs[:len(vs)-1]
+			}
+		default:
+			tree.Insert(v)
+			vs = append(vs, v)
+			sort.Ints(vs)
+		}
+
+		if err := checkTree(tree.Root); err != nil {
+			t.Fatal(err)
+		}
+
+		min, minOK := tree.PeekMin()
+		max, maxOK := tree.PeekMax()
+		if len(vs) == 0 {
+			if minOK || maxOK {
+				t.Fatal("empty tree has min or max")
+			}
+
+			continue
+		}
+
+		if min != vs[0] || max != vs[len(vs)-1] {
+			t.Fatal("unexpected peek", min, max, vs)
+		}
+	}
+}
+
+func TestTreePopMinClone(t *testing.T) {
+	tree := &rbt.Tree[int]{}
+	for i := 0; i < 10; i++ {
+		tree.Insert(i)
+	}
+
+	c := tree.Clone()
+	for i := 0; i < 10; i++ {
+		if v, _ := tree.PopMin(); v != i {
+			t.Fatal("unexpected min", v)
+		}
+
+		if v, _ := c.PopMax(); v != 9-i {
+			t.Fatal("unexpected max", v)
+		}
+	}
+
+	if _, ok := tree.PopMin(); ok {
+		t.Fatal("value is popped from empty tree")
+	}
+}
+
+func TestTreeCmpPopMinMax(t *testing.T) {
+	tree := &rbt.TreeCmp[int]{Cmp: compare.Reverse(compare.Ordered[int])}
+	for _, v := range rand.Perm(100) {
+		tree.Insert(v)
+	}
+
+	// comparator is reversed so min is the greatest int
+	for i := 0; i < 50; i++ {
+		if v, _ := tree.PopMin(); v != 99-i {
+			t.Fatal("unexpected min", v)
+		}
+
+		if v, _ := tree.PopMax(); v != i {
+			t.Fatal("unexpected max", v)
+		}
+
+		if err := checkTreeCmp(tree.Root); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if tree.Len() != 0 || tree.Root != nil {
+		t.Fatal("tree is not empty")
+	}
+}
+
+func BenchmarkTreePopMin(b *testing.B) {
+	tree := &rbt.Tree[int]{}
+	for i := 0; i < b.N; i++ {
+		tree.Insert(rand.Int())
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.PopMin()
+	}
+}
@@ -79,6 +79,64 @@ func BenchmarkTreeInsert(b *testing.B) {
 	}
 }
 
+// BenchmarkBTreeInsertAppend inserts distinct ascending keys, it is
+// comparable with BenchmarkTreeInsertAppend.
+func BenchmarkBTreeInsertAppend(b *testing.B) {
+	tree := &rbt.BTree[int]{}
+	for i := 0; i < b.N; i++ {
+		tree.Insert(i)
+	}
+}
+
+func BenchmarkTreeInsertRandom(b *testing.B) {
+	benchmarkInsertRandom(b, &rbt.Tree[int]{})
+}
+
+func BenchmarkBTreeInsertRandom(b *testing.B) {
+	benchmarkInsertRandom(b, &rbt.BTree[int]{})
+}
+
+func BenchmarkTreeFind(b *testing.B) {
+	benchmarkFind(b, &rbt.Tree[int]{})
+}
+
+func BenchmarkBTreeFind(b *testing.B) {
+	benchmarkFind(b, &rbt.BTree[int]{})
+}
+
+func benchmarkInsertRandom(b *testing.B, tree rbt.OrderedSet[int]) {
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < b.N; i++ {
+		tree.Insert(r.Int())
+	}
+}
+
+func benchmarkFind(b *testing.B, tree rbt.OrderedSet[int]) {
+	n := 1 << 20
+	vs := rand.New(rand.NewSource(1)).Perm(n)
+
+	for _, v := range vs {
+		tree.Insert(v)
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		tree.Contains(vs[i%n])
+	}
+}
+
+// treeValues returns all values of tree in order.
+func treeValues[T constraints.Ordered](tree *rbt.Tree[T]) []T {
+	vs := []T{}
+	for n := tree.Root.Min(); n != nil; n = n.Successor() {
+		vs = append(vs, n.Value)
+	}
+
+	return vs
+}
+
 // checkTree checks that all red-black propwerties are valid for tree n.
 // n should be root node for tree.
 // checkTree checks:
@@ -0,0 +1,290 @@
+package rbt
+
+// Seq represents sequence of values with positional access. Values are
+// kept in red-black tree of NodeCmp ordered by position instead of Cmp,
+// every node knows size of its subtree, so values are found, inserted and
+// deleted by index in O(log n). Zero Seq is empty sequence.
+type Seq[T any] struct {
+	Root *NodeCmp[T]
+}
+
+// Len returns number of values in s.
+func (s *Seq[T]) Len() int {
+	return s.Root.subtreeSize()
+}
+
+// Get returns value at index i. It panics if i is out of range.
+func (s *Seq[T]) Get(i int) T {
+	return s.node(i).Value
+}
+
+// Set sets value at index i. It panics if i is out of range.
+func (s *Seq[T]) Set(i int, v T) {
+	s.node(i).Value = v
+}
+
+// InsertAt inserts v at index i, so values from i are moved right.
+// i can be equal to Len to append v. It panics if i is out of range.
+func (s *Seq[T]) InsertAt(i int, v T) {
+	if i < 0 || i > s.Len() {
+		panic("index out of range")
+	}
+
+	nn := &NodeCmp[T]{
+		Value: v,
+		Red:   true,
+		size:  1,
+	}
+
+	if s.Root == nil {
+		nn.Red = false
+		s.Root = nn
+		return
+	}
+
+	// new node becomes left child of node at i or right child of its
+	// predecessor, or right child of max when v is appended
+	var p *NodeCmp[T]
+	if i == s.Len() {
+		p = s.Root.Max()
+		p.SetRight(nn)
+	} else if p = s.node(i); p.Left == nil {
+		p.SetLeft(nn)
+	} else {
+		p = p.Left.Max()
+		p.SetRight(nn)
+	}
+
+	for ; p != nil; p = p.Parent {
+		p.size++
+	}
+
+	s.Root = root(nn.insertFixup())
+}
+
+// DeleteAt deletes value at index i and returns it. It panics if i is out of range.
+func (s *Seq[T]) DeleteAt(i int) T {
+	n := s.node(i)
+	v := n.Value
+	s.Root = root(n.delete(true))
+
+	return v
+}
+
+// Slice returns values from index i to j (exclusive). It panics if indexes are out of range.
+func (s *Seq[T]) Slice(i, j int) []T {
+	if i < 0 || j > s.Len() || i > j {
+		panic("index out of range")
+	}
+
+	vs := make([]T, 0, j-i)
+	if i == j {
+		return vs
+	}
+
+	for n := s.node(i); len(vs) < j-i; n = n.Successor() {
+		vs = append(vs, n.Value)
+	}
+
+	return vs
+}
+
+// Ascend calls fn for values of s in order until fn returns false.
+func (s *Seq[T]) Ascend(fn func(v T) bool) {
+	for n := s.Root.Min(); n != nil; n = n.Successor() {
+		if !fn(n.Value) {
+			return
+		}
+	}
+}
+
+// Concat appends all values of o to s in O(log n), o becomes empty.
+func (s *Seq[T]) Concat(o *Seq[T]) {
+	if o.Root == nil {
+		return
+	}
+
+	if s.Root == nil {
+		s.Root, o.Root = o.Root, nil
+		return
+	}
+
+	// the first node of o is joining node for both trees, it has no left
+	// child, so it is removed physically
+	k := o.node(0)
+	r := root(k.delete(true))
+	o.Root = nil
+
+	s.Root, _ = join(s.Root, blackHeight(s.Root), k, r, blackHeight(r))
+}
+
+// Split moves values from index i to the end of s to new sequence and
+// returns it in O(log n). It panics if i is out of range.
+func (s *Seq[T]) Split(i int) *Seq[T] {
+	if i < 0 || i > s.Len() {
+		panic("index out of range")
+	}
+
+	l, _, r, _ := split(s.Root, blackHeight(s.Root), i)
+	s.Root = l
+
+	return &Seq[T]{Root: r}
+}
+
+// node returns node at index i.
+func (s *Seq[T]) node(i int) *NodeCmp[T] {
+	if i < 0 || i >= s.Len() {
+		panic("index out of range")
+	}
+
+	n := s.Root
+	for {
+		l := n.Left.subtreeSize()
+		if i < l {
+			n = n.Left
+		} else if i > l {
+			i -= l + 1
+			n = n.Right
+		} else {
+			return n
+		}
+	}
+}
+
+// root returns root of tree with node n, root is painted black.
+func root[T any](n *NodeCmp[T]) *NodeCmp[T] {
+	if n == nil {
+		return nil
+	}
+
+	for n.Parent != nil {
+		n = n.Parent
+	}
+
+	n.Red = false
+
+	return n
+}
+
+// blackHeight returns number of black nodes from n to nil leafs.
+func blackHeight[T any](n *NodeCmp[T]) int {
+	h := 0
+	for ; n != nil; n = n.Left {
+		if !n.Red {
+			h++
+		}
+	}
+
+	return h
+}
+
+// join returns root of tree with all nodes of tree l, then node k and then
+// all nodes of tree r, and its black height. Roots of l and r must be black,
+// lh and rh are their black heights. k is linked to the tree with greater
+// black height at the node of the same black height as the other tree, then
+// red-black properties are restored as after insert. join takes O(|lh - rh| + 1).
+func join[T any](l *NodeCmp[T], lh int, k, r *NodeCmp[T], rh int) (*NodeCmp[T], int) {
+	k.Parent = nil
+
+	if lh == rh {
+		k.Red = false
+		k.SetLeft(l)
+		k.SetRight(r)
+		k.update()
+
+		return k, lh + 1
+	}
+
+	var p *NodeCmp[T]
+	k.Red = true
+
+	h := lh
+	if lh > rh {
+		c := l
+		for !(c.Black() && lh == rh) {
+			if c.Black() {
+				lh--
+			}
+
+			p, c = c, c.Right
+		}
+
+		k.SetLeft(c)
+		k.SetRight(r)
+		p.SetRight(k)
+	} else {
+		h = rh
+		c := r
+		for !(c.Black() && rh == lh) {
+			if c.Black() {
+				rh--
+			}
+
+			p, c = c, c.Left
+		}
+
+		k.SetLeft(l)
+		k.SetRight(c)
+		p.SetLeft(k)
+	}
+
+	// path from p to the root is as long as the walk down to p
+	k.update()
+	for ; p != nil; p = p.Parent {
+		p.update()
+	}
+
+	// fixup ends at the root only if red uncle case goes up to it, then
+	// the root is painted black and black height grows
+	n := k.insertFixup()
+	if n.Parent == nil {
+		h++
+	}
+
+	return root(n), h
+}
+
+// split splits tree n of black height h to trees with first i nodes and
+// the rest nodes and returns them with their black heights. Every node on
+// the path to split point is detached and joins subtrees on its side.
+// Costs of joins on each side sum up to O(h), so split takes O(log n).
+func split[T any](n *NodeCmp[T], h, i int) (*NodeCmp[T], int, *NodeCmp[T], int) {
+	if n == nil {
+		return nil, 0, nil, 0
+	}
+
+	if n.Black() {
+		h--
+	}
+
+	l, r := n.Left, n.Right
+	n.Left, n.Right = nil, nil
+	l, lh := detach(l, h)
+	r, rh := detach(r, h)
+
+	if i <= l.subtreeSize() {
+		ll, llh, lr, lrh := split(l, lh, i)
+		r, rh = join(lr, lrh, n, r, rh)
+
+		return ll, llh, r, rh
+	}
+
+	rl, rlh, rr, rrh := split(r, rh, i-l.subtreeSize()-1)
+	l, lh = join(l, lh, n, rl, rlh)
+
+	return l, lh, rr, rrh
+}
+
+// detach makes n of black height h root of separate tree and returns it
+// with its new black height.
+func detach[T any](n *NodeCmp[T], h int) (*NodeCmp[T], int) {
+	if n != nil {
+		n.Parent = nil
+		if n.Red {
+			n.Red = false
+			h++
+		}
+	}
+
+	return n, h
+}
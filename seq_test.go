@@ -0,0 +1,152 @@
+package rbt_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"gotest.com/rbt"
+)
+
+// checkSeq checks that s is valid red-black tree with values vs.
+func checkSeq(t *testing.T, s *rbt.Seq[int], vs []int) {
+	t.Helper()
+
+	if err := checkTreeCmp(s.Root); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Len() != len(vs) {
+		t.Fatal("unexpected len", s.Len(), len(vs))
+	}
+
+	// Get finds values by subtree sizes
+	for i, v := range vs {
+		if s.Get(i) != v {
+			t.Fatal("unexpected value at", i, s.Get(i), v)
+		}
+	}
+}
+
+func TestSeq(t *testing.T) {
+	s := &rbt.Seq[int]{}
+	vs := []int{}
+
+	for i := 0; i < 1000; i++ {
+		if len(vs) > 0 && rand.Intn(3) == 0 {
+			j := rand.Intn(len(vs))
+			if v := s.DeleteAt(j); v != vs[j] {
+				t.Fatal("unexpected deleted value", v, vs[j])
+			}
+
+			vs = append(vs[:j], vs[j+1:]...)
+		} else {
+			j := rand.Intn(len(vs) + 1)
+			s.InsertAt(j, i)
+			vs = append(vs[:j], append([]int{i}, vs[j:]...)...)
+		}
+
+		if i%50 == 0 {
+			checkSeq(t, s, vs)
+		}
+	}
+
+	checkSeq(t, s, vs)
+
+	s.Set(3, -1)
+	vs[3] = -1
+
+	if got := s.Slice(2, 10); !reflect.DeepEqual(got, vs[2:10]) {
+		t.Fatal("unexpected slice", got)
+	}
+
+	if got := s.Slice(5, 5); len(got) != 0 {
+		t.Fatal("unexpected empty slice", got)
+	}
+}
+
+func TestSeqSplitConcat(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 10, 100, 300} {
+		for _, i := range []int{0, n / 3, n / 2, n} {
+			s := &rbt.Seq[int]{}
+			vs := []int{}
+			for j := 0; j < n; j++ {
+				s.InsertAt(j, j)
+				vs = append(vs, j)
+			}
+
+			r := s.Split(i)
+			checkSeq(t, s, vs[:i])
+			checkSeq(t, r, vs[i:])
+
+			// concat in reverse order, then restore original one
+			r.Concat(s)
+			checkSeq(t, r, append(append([]int{}, vs[i:]...), vs[:i]...))
+
+			if s.Len() != 0 {
+				t.Fatal("concatenated sequence is not empty")
+			}
+
+			l := r.Split(n - i)
+			l.Concat(r)
+			checkSeq(t, l, vs)
+		}
+	}
+}
+
+func TestSeqConcatUnbalanced(t *testing.T) {
+	a, b := &rbt.Seq[int]{}, &rbt.Seq[int]{}
+	vs := []int{}
+
+	for i := 0; i < 500; i++ {
+		a.InsertAt(i, i)
+		vs = append(vs, i)
+	}
+
+	for i := 0; i < 3; i++ {
+		b.InsertAt(i, 500+i)
+	}
+
+	// short tree is joined into the right spine of the tall one and back
+	a.Concat(b)
+	checkSeq(t, a, append(vs, 500, 501, 502))
+
+	c := &rbt.Seq[int]{}
+	c.InsertAt(0, -1)
+	c.Concat(a)
+	checkSeq(t, c, append([]int{-1}, append(vs, 500, 501, 502)...))
+}
+
+func TestSeqRandomSplitConcat(t *testing.T) {
+	s := &rbt.Seq[int]{}
+	vs := []int{}
+
+	for i := 0; i < 2000; i++ {
+		switch op := rand.Intn(10); {
+		case op < 5 || len(vs) == 0:
+			j := rand.Intn(len(vs) + 1)
+			s.InsertAt(j, i)
+			vs = append(vs[:j], append([]int{i}, vs[j:]...)...)
+		case op < 7:
+			j := rand.Intn(len(vs))
+			s.DeleteAt(j)
+			vs = append(vs[:j], vs[j+1:]...)
+		default:
+			// split at random index and concat halves in random order
+			j := rand.Intn(len(vs) + 1)
+			r := s.Split(j)
+			checkSeq(t, s, vs[:j])
+			checkSeq(t, r, vs[j:])
+
+			if rand.Intn(2) == 0 {
+				s.Concat(r)
+			} else {
+				r.Concat(s)
+				s = r
+				vs = append(append([]int{}, vs[j:]...), vs[:j]...)
+			}
+		}
+
+		checkSeq(t, s, vs)
+	}
+}
@@ -0,0 +1,381 @@
+package rbt
+
+import (
+	"constraints"
+	"sort"
+	"sync"
+
+	"gotest.com/rbt/compare"
+)
+
+const (
+	// DefaultMaxShardLen is max number of values in shard of Sharded
+	// with zero MaxShardLen.
+	DefaultMaxShardLen = 1 << 16
+)
+
+// Sharded represents ordered set that is safe for concurrent use. Values
+// are partitioned by key ranges into shards, every shard is a Tree with
+// its own lock, so writers to different ranges do not block each other.
+// Shard is split in half when it grows over MaxShardLen and when it shrinks
+// under a quarter of MaxShardLen it is merged with neighbour if they have
+// no more than a half of MaxShardLen together. Shard of equal values
+// cannot be split, it is tried again only when shard doubles.
+// Iteration and range queries go over shards in order, each shard is
+// locked only while it is read, so they see values of every shard
+// consistently but not the whole set at one moment.
+type Sharded[T constraints.Ordered] struct {
+	// MaxShardLen is max number of values in shard, zero means DefaultMaxShardLen.
+	// It must not be changed after first insert.
+	MaxShardLen int
+
+	mu     sync.RWMutex // guards shards slice, not shards content
+	shards []*shard[T]
+}
+
+// shard keeps values from lo (inclusive) to lo of next shard (exclusive).
+// The first shard has no lower bound.
+type shard[T constraints.Ordered] struct {
+	mu   sync.RWMutex
+	lo   T
+	tree Tree[T]
+	// maxLen is length over which shard is split, zero means MaxShardLen.
+	// It is raised if all values of shard are equal and it cannot be split.
+	maxLen int
+}
+
+// Insert inserts v.
+func (s *Sharded[T]) Insert(v T) {
+	// sh is locked before s.mu is released, split and merge lock s.mu
+	// and then shards, so sh can not be replaced while it is changed
+	s.mu.RLock()
+	sh := s.find(v)
+	sh.mu.Lock()
+	s.mu.RUnlock()
+
+	sh.tree.Insert(v)
+	split := sh.tree.Len() > sh.splitLen(s.maxShardLen())
+	sh.mu.Unlock()
+
+	if split {
+		s.split(sh)
+	}
+}
+
+// Delete deletes v and returns true if v was found.
+func (s *Sharded[T]) Delete(v T) bool {
+	s.mu.RLock()
+	if len(s.shards) == 0 {
+		s.mu.RUnlock()
+		return false
+	}
+
+	sh := s.find(v)
+	sh.mu.Lock()
+	s.mu.RUnlock()
+
+	ok := sh.tree.Delete(v)
+	merge := sh.tree.Len() < s.maxShardLen()/4
+	sh.mu.Unlock()
+
+	if merge && s.canMerge(sh, v) {
+		s.merge(sh)
+	}
+
+	return ok
+}
+
+// Contains returns true if v is in s.
+func (s *Sharded[T]) Contains(v T) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.shards) == 0 {
+		return false
+	}
+
+	sh := s.find(v)
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	return sh.tree.Contains(v)
+}
+
+// Min returns min value or false if s is empty.
+func (s *Sharded[T]) Min() (T, bool) {
+	var v T
+	ok := false
+
+	s.Ascend(func(m T) bool {
+		v, ok = m, true
+		return false
+	})
+
+	return v, ok
+}
+
+// Max returns max value or false if s is empty.
+func (s *Sharded[T]) Max() (T, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for i := len(s.shards) - 1; i >= 0; i-- {
+		sh := s.shards[i]
+		sh.mu.RLock()
+		v, ok := sh.tree.Max()
+		sh.mu.RUnlock()
+
+		if ok {
+			return v, true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+// Len returns number of values in s.
+func (s *Sharded[T]) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l := 0
+	for _, sh := range s.shards {
+		sh.mu.RLock()
+		l += sh.tree.Len()
+		sh.mu.RUnlock()
+	}
+
+	return l
+}
+
+// Shards returns number of shards.
+func (s *Sharded[T]) Shards() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.shards)
+}
+
+// Ascend calls fn for values in ascending order until fn returns false.
+func (s *Sharded[T]) Ascend(fn func(v T) bool) {
+	s.Range(KeyRange[T]{LoInf: true, HiInf: true}, fn)
+}
+
+// Range calls fn for values in range r in ascending order until fn returns false.
+// Shard that is read is locked while fn is called, so fn must not call
+// methods of s.
+func (s *Sharded[T]) Range(r KeyRange[T], fn func(v T) bool) {
+	// shards are copied, so split and merge are not blocked until the end
+	// of iteration, replaced shard keeps its values
+	s.mu.RLock()
+	i := 0
+	if !r.LoInf && len(s.shards) > 0 {
+		i = s.index(r.Lo)
+	}
+
+	shards := append([]*shard[T]{}, s.shards...)
+	s.mu.RUnlock()
+
+	for ; i < len(shards); i++ {
+		sh := shards[i]
+		if !r.HiInf && i > 0 && compare.Ordered(sh.lo, r.Hi) >= 0 {
+			return
+		}
+
+		sh.mu.RLock()
+		n := sh.tree.Root.Min()
+		if !r.LoInf {
+			n = sh.tree.Root.LowerBound(r.Lo)
+		}
+
+		for ; n != nil && (r.HiInf || compare.Ordered(n.Value, r.Hi) < 0); n = n.Successor() {
+			if !fn(n.Value) {
+				sh.mu.RUnlock()
+				return
+			}
+		}
+		sh.mu.RUnlock()
+	}
+}
+
+func (s *Sharded[T]) maxShardLen() int {
+	if s.MaxShardLen == 0 {
+		return DefaultMaxShardLen
+	}
+
+	return s.MaxShardLen
+}
+
+// find returns shard for v, s.mu must be held. The first shard is created if
+// s is empty.
+func (s *Sharded[T]) find(v T) *shard[T] {
+	if len(s.shards) == 0 {
+		// upgrade lock to create first shard
+		s.mu.RUnlock()
+		s.mu.Lock()
+		if len(s.shards) == 0 {
+			s.shards = []*shard[T]{{}}
+		}
+		s.mu.Unlock()
+		s.mu.RLock()
+	}
+
+	return s.shards[s.index(v)]
+}
+
+// index returns index of shard for v, s.mu must be held.
+func (s *Sharded[T]) index(v T) int {
+	// first shard with lo greater than v is next to the shard of v
+	i := sort.Search(len(s.shards)-1, func(i int) bool {
+		return compare.Ordered(s.shards[i+1].lo, v) > 0
+	})
+
+	return i
+}
+
+// split splits sh in two shards by median value.
+func (s *Sharded[T]) split(sh *shard[T]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	// sh could be split already by another goroutine
+	i := s.indexOf(sh)
+	if i < 0 || sh.tree.Len() <= sh.splitLen(s.maxShardLen()) {
+		return
+	}
+
+	vs := make([]T, 0, sh.tree.Len())
+	sh.tree.Ascend(func(v T) bool {
+		vs = append(vs, v)
+		return true
+	})
+
+	// duplicates of median must stay in one shard, they go to the right
+	// half or to the left one if they start from the first value
+	m := len(vs) / 2
+	for m > 0 && compare.Ordered(vs[m-1], vs[m]) == 0 {
+		m--
+	}
+
+	if m == 0 {
+		m = len(vs)/2 + 1
+		for m < len(vs) && compare.Ordered(vs[m-1], vs[m]) == 0 {
+			m++
+		}
+	}
+
+	if m == len(vs) {
+		// all values are equal, shard is not copied again until it doubles
+		sh.maxLen = 2 * len(vs)
+		return
+	}
+
+	l := &shard[T]{lo: sh.lo}
+	r := &shard[T]{lo: vs[m]}
+	l.tree.build(vs[:m])
+	r.tree.build(vs[m:])
+
+	s.shards = append(s.shards[:i], append([]*shard[T]{l, r}, s.shards[i+1:]...)...)
+}
+
+// canMerge returns true if sh of value v can be merged with its neighbour.
+// It takes only read locks, so Delete locks s.mu exclusively in merge
+// only when merge is likely to happen.
+func (s *Sharded[T]) canMerge(sh *shard[T], v T) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i := s.index(v)
+	if len(s.shards) == 1 || s.shards[i] != sh {
+		// sh is replaced by split or merge already
+		return false
+	}
+
+	// shards are locked one by one, Delete of neighbour holds its lock
+	n := sh.len()
+	for _, j := range []int{i - 1, i + 1} {
+		if j >= 0 && j < len(s.shards) && n+s.shards[j].len() <= s.maxShardLen()/2 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// merge merges sh with its right or left neighbour if they are small together.
+func (s *Sharded[T]) merge(sh *shard[T]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.indexOf(sh)
+	if i < 0 {
+		return
+	}
+
+	for _, j := range []int{i, i - 1} {
+		if j >= 0 && j+1 < len(s.shards) && s.mergeAt(j) {
+			return
+		}
+	}
+}
+
+// mergeAt merges shards i and i+1 if they have no more than a half of
+// MaxShardLen together, s.mu must be held exclusively.
+func (s *Sharded[T]) mergeAt(i int) bool {
+	a, b := s.shards[i], s.shards[i+1]
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if a.tree.Len()+b.tree.Len() > s.maxShardLen()/2 {
+		return false
+	}
+
+	vs := make([]T, 0, a.tree.Len()+b.tree.Len())
+	for _, t := range []*Tree[T]{&a.tree, &b.tree} {
+		t.Ascend(func(v T) bool {
+			vs = append(vs, v)
+			return true
+		})
+	}
+
+	m := &shard[T]{lo: a.lo}
+	m.tree.build(vs)
+
+	s.shards = append(s.shards[:i], append([]*shard[T]{m}, s.shards[i+2:]...)...)
+
+	return true
+}
+
+// splitLen returns length over which sh is split, max is MaxShardLen of
+// Sharded, sh.mu must be held.
+func (sh *shard[T]) splitLen(max int) int {
+	if sh.maxLen > max {
+		return sh.maxLen
+	}
+
+	return max
+}
+
+func (sh *shard[T]) len() int {
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+
+	return sh.tree.Len()
+}
+
+// indexOf returns index of sh or -1 if sh is not in s, s.mu must be held.
+func (s *Sharded[T]) indexOf(sh *shard[T]) int {
+	for i, x := range s.shards {
+		if x == sh {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,155 @@
+package rbt_test
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"gotest.com/rbt"
+)
+
+func TestShardedSplitMerge(t *testing.T) {
+	s := &rbt.Sharded[int]{MaxShardLen: 16}
+	expected := []int{}
+
+	for i := 0; i < 500; i++ {
+		v := rand.Intn(1000)
+		s.Insert(v)
+		expected = append(expected, v)
+	}
+
+	sort.Ints(expected)
+
+	if s.Shards() < 500/16 {
+		t.Fatal("shards are not split", s.Shards())
+	}
+
+	vs := []int{}
+	s.Ascend(func(v int) bool {
+		vs = append(vs, v)
+		return true
+	})
+
+	if !reflect.DeepEqual(vs, expected) || s.Len() != len(expected) {
+		t.Fatal("unexpected values", vs)
+	}
+
+	r := []int{}
+	s.Range(rbt.KeyRange[int]{Lo: 100, Hi: 300}, func(v int) bool {
+		r = append(r, v)
+		return true
+	})
+
+	lo, hi := sort.SearchInts(expected, 100), sort.SearchInts(expected, 300)
+	if !reflect.DeepEqual(r, expected[lo:hi]) {
+		t.Fatal("unexpected range", r)
+	}
+
+	if m, _ := s.Min(); m != expected[0] {
+		t.Fatal("unexpected min", m)
+	}
+
+	if m, _ := s.Max(); m != expected[len(expected)-1] {
+		t.Fatal("unexpected max", m)
+	}
+
+	for _, v := range expected {
+		if !s.Delete(v) {
+			t.Fatal("value is not deleted", v)
+		}
+	}
+
+	if s.Len() != 0 || s.Shards() != 1 {
+		t.Fatal("shards are not merged", s.Len(), s.Shards())
+	}
+}
+
+func TestShardedConcurrent(t *testing.T) {
+	s := &rbt.Sharded[int]{MaxShardLen: 64}
+	wg := sync.WaitGroup{}
+
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+
+		go func(w int) {
+			defer wg.Done()
+
+			for i := 0; i < 1000; i++ {
+				v := w*1000 + i
+				s.Insert(v)
+				if i%2 == 1 {
+					s.Delete(v)
+				}
+
+				s.Contains(v)
+			}
+		}(w)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 50; i++ {
+			prev := -1
+			s.Ascend(func(v int) bool {
+				if v <= prev {
+					t.Error("wrong order", prev, v)
+				}
+
+				prev = v
+				return true
+			})
+		}
+	}()
+
+	wg.Wait()
+
+	if s.Len() != 4000 {
+		t.Fatal("unexpected len", s.Len())
+	}
+}
+
+func TestShardedDuplicates(t *testing.T) {
+	s := &rbt.Sharded[int]{MaxShardLen: 4}
+
+	// duplicates of the first value stay in the left shard
+	for i := 0; i < 4; i++ {
+		s.Insert(1)
+	}
+
+	s.Insert(2)
+	if s.Shards() != 2 {
+		t.Fatal("shard is not split", s.Shards())
+	}
+
+	for i := 0; i < 1000; i++ {
+		s.Insert(3)
+	}
+
+	// shard of equal values is not copied by every insert, AllocsPerRun
+	// inserts 101 values
+	allocs := testing.AllocsPerRun(100, func() {
+		s.Insert(3)
+	})
+
+	if allocs > 2 {
+		t.Fatal("shard of duplicates is split on insert", allocs)
+	}
+
+	if s.Len() != 1106 || s.Shards() != 3 {
+		t.Fatal("unexpected len", s.Len(), s.Shards())
+	}
+
+	n := 0
+	s.Range(rbt.KeyRange[int]{Lo: 3, HiInf: true}, func(v int) bool {
+		n++
+		return true
+	})
+
+	if n != 1101 {
+		t.Fatal("unexpected number of duplicates", n)
+	}
+}
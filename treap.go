@@ -0,0 +1,195 @@
+package rbt
+
+import (
+	"constraints"
+
+	"gotest.com/rbt/compare"
+)
+
+// Treap represents randomized search tree. Every node has random priority
+// and tree is a heap by priorities, that keeps it balanced with high probability.
+// Priorities are generated from Seed, so same Seed and same operations
+// give the same tree.
+type Treap[T constraints.Ordered] struct {
+	Root  *TreapNode[T]
+	Seed  uint64
+	count int
+}
+
+type TreapNode[T constraints.Ordered] struct {
+	Left     *TreapNode[T]
+	Right    *TreapNode[T]
+	Priority uint64
+	Value    T
+}
+
+// Insert inserts v to t.
+func (t *Treap[T]) Insert(v T) {
+	t.Root = t.Root.insert(v, t.priority())
+	t.count++
+}
+
+// Delete deletes v from t and returns true if v was found.
+func (t *Treap[T]) Delete(v T) bool {
+	var ok bool
+	t.Root, ok = t.Root.delete(v)
+	if ok {
+		t.count--
+	}
+
+	return ok
+}
+
+// Contains returns true if t contains value v.
+func (t *Treap[T]) Contains(v T) bool {
+	n := t.Root
+	for n != nil {
+		c := compare.Ordered(v, n.Value)
+		if c == 0 {
+			return true
+		} else if c > 0 {
+			n = n.Right
+		} else {
+			n = n.Left
+		}
+	}
+
+	return false
+}
+
+// Min returns min value in t or false if t is empty.
+func (t *Treap[T]) Min() (T, bool) {
+	var zero T
+	if t.Root == nil {
+		return zero, false
+	}
+
+	n := t.Root
+	for n.Left != nil {
+		n = n.Left
+	}
+
+	return n.Value, true
+}
+
+// Max returns max value in t or false if t is empty.
+func (t *Treap[T]) Max() (T, bool) {
+	var zero T
+	if t.Root == nil {
+		return zero, false
+	}
+
+	n := t.Root
+	for n.Right != nil {
+		n = n.Right
+	}
+
+	return n.Value, true
+}
+
+// Len returns number of values in t.
+func (t *Treap[T]) Len() int {
+	return t.count
+}
+
+// Ascend calls fn for values of t in ascending order until fn returns false.
+func (t *Treap[T]) Ascend(fn func(v T) bool) {
+	t.Root.ascend(fn)
+}
+
+// priority returns next pseudo random priority (xorshift64*).
+func (t *Treap[T]) priority() uint64 {
+	if t.Seed == 0 {
+		t.Seed = 0x9e3779b97f4a7c15
+	}
+
+	t.Seed ^= t.Seed >> 12
+	t.Seed ^= t.Seed << 25
+	t.Seed ^= t.Seed >> 27
+
+	return t.Seed * 0x2545f4914f6cdd1d
+}
+
+func (n *TreapNode[T]) rotateLeft() *TreapNode[T] {
+	c := n.Right
+	n.Right = c.Left
+	c.Left = n
+
+	return c
+}
+
+func (n *TreapNode[T]) rotateRight() *TreapNode[T] {
+	b := n.Left
+	n.Left = b.Right
+	b.Right = n
+
+	return b
+}
+
+func (n *TreapNode[T]) insert(v T, p uint64) *TreapNode[T] {
+	if n == nil {
+		return &TreapNode[T]{
+			Value:    v,
+			Priority: p,
+		}
+	}
+
+	if compare.Ordered(v, n.Value) > 0 {
+		n.Right = n.Right.insert(v, p)
+		if n.Right.Priority > n.Priority {
+			return n.rotateLeft()
+		}
+	} else {
+		n.Left = n.Left.insert(v, p)
+		if n.Left.Priority > n.Priority {
+			return n.rotateRight()
+		}
+	}
+
+	return n
+}
+
+func (n *TreapNode[T]) delete(v T) (*TreapNode[T], bool) {
+	if n == nil {
+		return nil, false
+	}
+
+	var ok bool
+	c := compare.Ordered(v, n.Value)
+	if c == 0 {
+		return n.Left.merge(n.Right), true
+	} else if c > 0 {
+		n.Right, ok = n.Right.delete(v)
+	} else {
+		n.Left, ok = n.Left.delete(v)
+	}
+
+	return n, ok
+}
+
+// merge joins treaps n and r, all values of n must be less or equal to values of r.
+func (n *TreapNode[T]) merge(r *TreapNode[T]) *TreapNode[T] {
+	if n == nil {
+		return r
+	}
+
+	if r == nil {
+		return n
+	}
+
+	if n.Priority > r.Priority {
+		n.Right = n.Right.merge(r)
+		return n
+	}
+
+	r.Left = n.merge(r.Left)
+	return r
+}
+
+func (n *TreapNode[T]) ascend(fn func(v T) bool) bool {
+	if n == nil {
+		return true
+	}
+
+	return n.Left.ascend(fn) && fn(n.Value) && n.Right.ascend(fn)
+}
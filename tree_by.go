@@ -0,0 +1,133 @@
+package rbt
+
+import (
+	"constraints"
+
+	"gotest.com/rbt/compare"
+)
+
+// TreeBy represents red-black tree of values ordered by their keys. Values
+// are stored in TreeCmp, but lookups, deletes and range queries take keys,
+// so value does not have to be constructed to find it. Zero TreeBy is
+// ready to use when Key is set.
+type TreeBy[T any, K constraints.Ordered] struct {
+	// Key returns key of value. It must not be changed after first insert.
+	Key  func(v T) K
+	tree TreeCmp[T]
+}
+
+// NewTreeBy returns empty tree of values ordered by key.
+func NewTreeBy[T any, K constraints.Ordered](key func(v T) K) *TreeBy[T, K] {
+	return &TreeBy[T, K]{Key: key}
+}
+
+// Insert inserts v.
+func (t *TreeBy[T, K]) Insert(v T) {
+	if t.tree.Cmp == nil {
+		t.tree.Cmp = func(a, b T) int {
+			return compare.Ordered(t.Key(a), t.Key(b))
+		}
+	}
+
+	t.tree.Insert(v)
+}
+
+// Delete deletes value with key k and returns true if it was found.
+func (t *TreeBy[T, K]) Delete(k K) bool {
+	n := t.find(k)
+	if n == nil {
+		return false
+	}
+
+	t.tree.deleteNode(t.tree.mutNode(n))
+
+	return true
+}
+
+// Contains returns true if t contains value with key k.
+func (t *TreeBy[T, K]) Contains(k K) bool {
+	return t.find(k) != nil
+}
+
+// Find returns value with key k or false if there is no such value.
+func (t *TreeBy[T, K]) Find(k K) (T, bool) {
+	n := t.find(k)
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+
+	return n.Value, true
+}
+
+// Min returns value with min key or false if t is empty.
+func (t *TreeBy[T, K]) Min() (T, bool) {
+	return t.tree.Min()
+}
+
+// Max returns value with max key or false if t is empty.
+func (t *TreeBy[T, K]) Max() (T, bool) {
+	return t.tree.Max()
+}
+
+// Root returns root node of t.
+func (t *TreeBy[T, K]) Root() *NodeCmp[T] {
+	return t.tree.Root
+}
+
+// Len returns number of values in t.
+func (t *TreeBy[T, K]) Len() int {
+	return t.tree.Len()
+}
+
+// Ascend calls fn for values of t in ascending order of keys until fn returns false.
+func (t *TreeBy[T, K]) Ascend(fn func(v T) bool) {
+	t.tree.Ascend(fn)
+}
+
+// Range calls fn for values with keys in range r in ascending order of
+// keys until fn returns false.
+func (t *TreeBy[T, K]) Range(r KeyRange[K], fn func(v T) bool) {
+	n := t.tree.Root.Min()
+	if !r.LoInf {
+		n = t.lowerBound(r.Lo)
+	}
+
+	for ; n != nil && (r.HiInf || compare.Ordered(t.Key(n.Value), r.Hi) < 0); n = n.Successor() {
+		if !fn(n.Value) {
+			return
+		}
+	}
+}
+
+// find finds node with key k.
+func (t *TreeBy[T, K]) find(k K) *NodeCmp[T] {
+	n := t.tree.Root
+	for n != nil {
+		c := compare.Ordered(k, t.Key(n.Value))
+		if c == 0 {
+			return n
+		} else if c > 0 {
+			n = n.Right
+		} else {
+			n = n.Left
+		}
+	}
+
+	return nil
+}
+
+// lowerBound finds first node with key that is not less than k.
+func (t *TreeBy[T, K]) lowerBound(k K) *NodeCmp[T] {
+	var lb *NodeCmp[T]
+	for n := t.tree.Root; n != nil; {
+		if compare.Ordered(t.Key(n.Value), k) < 0 {
+			n = n.Right
+		} else {
+			lb = n
+			n = n.Left
+		}
+	}
+
+	return lb
+}
@@ -0,0 +1,58 @@
+package rbt_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"gotest.com/rbt"
+)
+
+type session struct {
+	id   int
+	user string
+}
+
+func TestTreeBy(t *testing.T) {
+	tree := rbt.NewTreeBy(func(s session) int { return s.id })
+
+	for _, id := range rand.Perm(100) {
+		tree.Insert(session{id: id, user: "u"})
+	}
+
+	if s, ok := tree.Find(42); !ok || s.id != 42 || s.user != "u" {
+		t.Fatal("value is not found by key", s, ok)
+	}
+
+	for id := 0; id < 100; id += 2 {
+		if !tree.Delete(id) {
+			t.Fatal("value is not deleted", id)
+		}
+	}
+
+	if tree.Delete(2) || tree.Contains(2) || !tree.Contains(3) || tree.Len() != 50 {
+		t.Fatal("unexpected delete")
+	}
+
+	if err := checkTreeCmp(tree.Root()); err != nil {
+		t.Fatal(err)
+	}
+
+	ids := []int{}
+	tree.Range(rbt.KeyRange[int]{Lo: 10, Hi: 20}, func(s session) bool {
+		ids = append(ids, s.id)
+		return true
+	})
+
+	if !reflect.DeepEqual(ids, []int{11, 13, 15, 17, 19}) {
+		t.Fatal("unexpected range", ids)
+	}
+
+	if min, _ := tree.Min(); min.id != 1 {
+		t.Fatal("unexpected min", min)
+	}
+
+	if max, _ := tree.Max(); max.id != 99 {
+		t.Fatal("unexpected max", max)
+	}
+}
@@ -0,0 +1,119 @@
+package rbt
+
+import (
+	"context"
+)
+
+// Comparable is implemented by types that compare themselves with other
+// values of the same type. Compare returns negative number if value is
+// less than other, zero if they are equal and positive number otherwise.
+type Comparable[T any] interface {
+	Compare(other T) int
+}
+
+// TreeOf represents red-black tree of values that implement Comparable.
+// It is TreeCmp with Cmp that calls Compare method, so it shares all
+// methods of TreeCmp. Zero TreeOf is ready to use, methods of TreeOf set
+// Cmp before they compare values. TreeCmp of zero TreeOf that was never
+// changed has nil Cmp.
+type TreeOf[T Comparable[T]] struct {
+	TreeCmp[T]
+}
+
+// NewTreeOf returns empty tree of values ordered by their Compare method.
+func NewTreeOf[T Comparable[T]]() *TreeOf[T] {
+	t := &TreeOf[T]{}
+	t.Cmp = compareOf[T]
+
+	return t
+}
+
+func compareOf[T Comparable[T]](a, b T) int {
+	return a.Compare(b)
+}
+
+func (t *TreeOf[T]) Insert(v T) {
+	t.init()
+	t.TreeCmp.Insert(v)
+}
+
+func (t *TreeOf[T]) Delete(v T) bool {
+	t.init()
+
+	return t.TreeCmp.Delete(v)
+}
+
+// Contains returns true if t contains value v.
+func (t *TreeOf[T]) Contains(v T) bool {
+	t.init()
+
+	return t.TreeCmp.Contains(v)
+}
+
+// Find returns value of t that is equal to v or false if there is no such value.
+func (t *TreeOf[T]) Find(v T) (T, bool) {
+	t.init()
+
+	return t.TreeCmp.Find(v)
+}
+
+// InsertBatch inserts values, see Tree.InsertBatch.
+func (t *TreeOf[T]) InsertBatch(values []T) {
+	t.init()
+	t.TreeCmp.InsertBatch(values)
+}
+
+// DeleteBatch deletes values and returns number of deleted values, see Tree.DeleteBatch.
+func (t *TreeOf[T]) DeleteBatch(values []T) int {
+	t.init()
+
+	return t.TreeCmp.DeleteBatch(values)
+}
+
+// InsertHint inserts v starting search from hint, see Tree.InsertHint.
+func (t *TreeOf[T]) InsertHint(hint *NodeCmp[T], v T) *NodeCmp[T] {
+	t.init()
+
+	return t.TreeCmp.InsertHint(hint, v)
+}
+
+// FindFrom finds node with value v starting search from node n of t, see Tree.FindFrom.
+func (t *TreeOf[T]) FindFrom(n *NodeCmp[T], v T) *NodeCmp[T] {
+	t.init()
+
+	return t.TreeCmp.FindFrom(n, v)
+}
+
+// AscendContext calls fn for values of t in ascending order, see TreeCmp.AscendContext.
+func (t *TreeOf[T]) AscendContext(ctx context.Context, fn func(v T) bool) error {
+	t.init()
+
+	return t.TreeCmp.AscendContext(ctx, fn)
+}
+
+// RangeContext calls fn for values in range r, see TreeCmp.RangeContext.
+func (t *TreeOf[T]) RangeContext(ctx context.Context, r KeyRange[T], fn func(v T) bool) error {
+	t.init()
+
+	return t.TreeCmp.RangeContext(ctx, r, fn)
+}
+
+// CursorAfter returns cursor that starts after last, see TreeCmp.CursorAfter.
+func (t *TreeOf[T]) CursorAfter(last T) *CursorCmp[T] {
+	t.init()
+
+	return t.TreeCmp.CursorAfter(last)
+}
+
+// Clone returns a copy of t in O(1), see TreeCmp.Clone.
+func (t *TreeOf[T]) Clone() *TreeOf[T] {
+	t.init()
+
+	return &TreeOf[T]{*t.TreeCmp.Clone()}
+}
+
+func (t *TreeOf[T]) init() {
+	if t.Cmp == nil {
+		t.Cmp = compareOf[T]
+	}
+}
@@ -0,0 +1,124 @@
+package rbt_test
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"gotest.com/rbt"
+)
+
+// version is semantic version that implements rbt.Comparable.
+type version struct {
+	major, minor, patch int
+}
+
+func (v version) Compare(o version) int {
+	if v.major != o.major {
+		return v.major - o.major
+	} else if v.minor != o.minor {
+		return v.minor - o.minor
+	}
+
+	return v.patch - o.patch
+}
+
+func TestTreeOf(t *testing.T) {
+	tree := &rbt.TreeOf[version]{}
+	vs := []version{}
+
+	for i := 0; i < 200; i++ {
+		v := version{rand.Intn(3), rand.Intn(3), rand.Intn(10)}
+		vs = append(vs, v)
+		tree.Insert(v)
+	}
+
+	if err := checkTreeCmp(tree.Root); err != nil {
+		t.Fatal(err)
+	}
+
+	prev := version{-1, 0, 0}
+	tree.Ascend(func(v version) bool {
+		if v.Compare(prev) < 0 {
+			t.Fatal("wrong order", prev, v)
+		}
+
+		prev = v
+		return true
+	})
+
+	c := tree.Clone()
+	for _, v := range vs {
+		if !tree.Delete(v) {
+			t.Fatal("value is not deleted", v)
+		}
+	}
+
+	if tree.Len() != 0 || c.Len() != len(vs) || !c.Contains(vs[0]) {
+		t.Fatal("unexpected len", tree.Len(), c.Len())
+	}
+
+	empty := rbt.NewTreeOf[version]()
+	if empty.Contains(version{}) {
+		t.Fatal("empty tree contains value")
+	}
+
+	var _ rbt.OrderedSet[version] = empty
+}
+
+func TestTreeOfZeroPromoted(t *testing.T) {
+	empty := &rbt.TreeOf[version]{}
+	if err := empty.RangeContext(context.Background(), rbt.KeyRange[version]{Lo: version{1, 0, 0}, HiInf: true}, func(v version) bool {
+		return true
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := empty.CursorAfter(version{1, 0, 0}).Next(); ok || empty.FindFrom(nil, version{}) != nil {
+		t.Fatal("value found in empty tree")
+	}
+
+	tree := &rbt.TreeOf[version]{}
+	tree.InsertBatch([]version{{1, 0, 0}, {0, 1, 0}, {2, 0, 0}})
+
+	n := tree.InsertHint(nil, version{0, 2, 0})
+	if tree.FindFrom(n, version{1, 0, 0}) == nil {
+		t.Fatal("value is not found from hint")
+	}
+
+	if v, ok := tree.PopMin(); !ok || v != (version{0, 1, 0}) {
+		t.Fatal("unexpected min", v)
+	}
+
+	if tree.DeleteBatch([]version{{2, 0, 0}, {3, 0, 0}}) != 1 || tree.Len() != 2 {
+		t.Fatal("unexpected delete", tree.Len())
+	}
+
+	tree.EnableSubtreeHash(func(v version) uint64 { return uint64(v.major) })
+	if h := tree.RangeHash(rbt.KeyRange[version]{Lo: version{1, 0, 0}, HiInf: true}); h != tree.SubtreeHash()-tree.RangeHash(rbt.KeyRange[version]{LoInf: true, Hi: version{1, 0, 0}}) {
+		t.Fatal("unexpected range hash", h)
+	}
+
+	got := []version{}
+	tree.RangeContext(context.Background(), rbt.KeyRange[version]{LoInf: true, HiInf: true}, func(v version) bool {
+		got = append(got, v)
+		return true
+	})
+
+	c := tree.Cursor()
+	for _, v := range got {
+		if x, ok := c.Next(); !ok || x != v {
+			t.Fatal("unexpected cursor value", x, v)
+		}
+	}
+
+	if len(got) != 2 {
+		t.Fatal("unexpected values", got)
+	}
+
+	other := &rbt.TreeOf[version]{}
+	other.InsertBatch([]version{{1, 0, 0}, {0, 2, 0}})
+	if !rbt.EqualCmp(&tree.TreeCmp, &other.TreeCmp) {
+		t.Fatal("trees are not equal")
+	}
+}
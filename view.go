@@ -0,0 +1,188 @@
+package rbt
+
+import (
+	"constraints"
+	"errors"
+
+	"gotest.com/rbt/compare"
+)
+
+// ErrOutOfRange is returned by View.Insert for value that is out of view range.
+var ErrOutOfRange = errors.New("value is out of view range")
+
+// View is live view of values of Tree in range. View does not copy values,
+// changes of the tree are seen by the view and changes through the view
+// are done in the tree. Range of view is always in ascending order of
+// values, descending view only iterates from the greatest value.
+type View[T constraints.Ordered] struct {
+	tree *Tree[T]
+	r    KeyRange[T]
+	desc bool
+}
+
+// SubSet returns view of values of t from lo (inclusive) to hi (exclusive).
+func (t *Tree[T]) SubSet(lo, hi T) *View[T] {
+	return t.view().SubSet(lo, hi)
+}
+
+// HeadSet returns view of values of t that are less than hi.
+func (t *Tree[T]) HeadSet(hi T) *View[T] {
+	return t.view().HeadSet(hi)
+}
+
+// TailSet returns view of values of t that are not less than lo.
+func (t *Tree[T]) TailSet(lo T) *View[T] {
+	return t.view().TailSet(lo)
+}
+
+// Descending returns view of all values of t in descending order.
+func (t *Tree[T]) Descending() *View[T] {
+	return t.view().Descending()
+}
+
+func (t *Tree[T]) view() *View[T] {
+	return &View[T]{
+		tree: t,
+		r:    KeyRange[T]{LoInf: true, HiInf: true},
+	}
+}
+
+// SubSet returns view of values of v from lo (inclusive) to hi (exclusive).
+// Bounds are narrowed to range of v.
+func (v *View[T]) SubSet(lo, hi T) *View[T] {
+	return v.TailSet(lo).HeadSet(hi)
+}
+
+// HeadSet returns view of values of v that are less than hi.
+func (v *View[T]) HeadSet(hi T) *View[T] {
+	c := *v
+	if c.r.HiInf || compare.Ordered(hi, c.r.Hi) < 0 {
+		c.r.Hi, c.r.HiInf = hi, false
+	}
+
+	return &c
+}
+
+// TailSet returns view of values of v that are not less than lo.
+func (v *View[T]) TailSet(lo T) *View[T] {
+	c := *v
+	if c.r.LoInf || compare.Ordered(lo, c.r.Lo) > 0 {
+		c.r.Lo, c.r.LoInf = lo, false
+	}
+
+	return &c
+}
+
+// Descending returns view of the same values in reversed order.
+func (v *View[T]) Descending() *View[T] {
+	c := *v
+	c.desc = !c.desc
+
+	return &c
+}
+
+// InRange returns true if value x is in range of v.
+func (v *View[T]) InRange(x T) bool {
+	return (v.r.LoInf || compare.Ordered(x, v.r.Lo) >= 0) && (v.r.HiInf || compare.Ordered(x, v.r.Hi) < 0)
+}
+
+// Insert inserts x to the tree or returns ErrOutOfRange if x is out of range of v.
+func (v *View[T]) Insert(x T) error {
+	if !v.InRange(x) {
+		return ErrOutOfRange
+	}
+
+	v.tree.Insert(x)
+
+	return nil
+}
+
+// Delete deletes x from the tree and returns true if x was found in v.
+func (v *View[T]) Delete(x T) bool {
+	return v.InRange(x) && v.tree.Delete(x)
+}
+
+// Contains returns true if x is in v.
+func (v *View[T]) Contains(x T) bool {
+	return v.InRange(x) && v.tree.Contains(x)
+}
+
+// Min returns min value in v or false if v is empty.
+func (v *View[T]) Min() (T, bool) {
+	var it iter[T]
+	return nodeValue(v.first(&it))
+}
+
+// Max returns max value in v or false if v is empty.
+func (v *View[T]) Max() (T, bool) {
+	var it iter[T]
+	return nodeValue(v.last(&it))
+}
+
+// Len returns number of values in v in O(log n) if tree has EnableSubtreeSize,
+// or in O(log n + k) for k values in v otherwise.
+func (v *View[T]) Len() int {
+	return v.tree.countRange(v.r)
+}
+
+// Ascend calls fn for values of v in order of v until fn returns false,
+// that is descending order for descending view.
+func (v *View[T]) Ascend(fn func(x T) bool) {
+	var it iter[T]
+	if v.desc {
+		for n := v.last(&it); n != nil && (v.r.LoInf || compare.Ordered(n.Value, v.r.Lo) >= 0); n = it.prev() {
+			if !fn(n.Value) {
+				return
+			}
+		}
+
+		return
+	}
+
+	for n := v.first(&it); n != nil && (v.r.HiInf || compare.Ordered(n.Value, v.r.Hi) < 0); n = it.next() {
+		if !fn(n.Value) {
+			return
+		}
+	}
+}
+
+// first moves it to node of min value in v and returns it or nil.
+func (v *View[T]) first(it *iter[T]) *Node[T] {
+	n := it.min(v.tree.Root)
+	if !v.r.LoInf {
+		n = it.lowerBound(v.tree.Root, v.r.Lo)
+	}
+
+	if n == nil || !v.InRange(n.Value) {
+		return nil
+	}
+
+	return n
+}
+
+// last moves it to node of max value in v and returns it or nil.
+func (v *View[T]) last(it *iter[T]) *Node[T] {
+	var n *Node[T]
+	if v.r.HiInf {
+		n = it.max(v.tree.Root)
+	} else if n = it.lowerBound(v.tree.Root, v.r.Hi); n != nil {
+		n = it.prev()
+	} else {
+		n = it.max(v.tree.Root)
+	}
+
+	if n == nil || !v.InRange(n.Value) {
+		return nil
+	}
+
+	return n
+}
+
+func nodeValue[T constraints.Ordered](n *Node[T]) (T, bool) {
+	if n == nil {
+		var zero T
+		return zero, false
+	}
+
+	return n.Value, true
+}
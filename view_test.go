@@ -0,0 +1,113 @@
+package rbt_test
+
+import (
+	"reflect"
+	"testing"
+
+	"gotest.com/rbt"
+)
+
+func viewValues(v *rbt.View[int]) []int {
+	vs := []int{}
+	v.Ascend(func(x int) bool {
+		vs = append(vs, x)
+		return true
+	})
+
+	return vs
+}
+
+func TestView(t *testing.T) {
+	tree := &rbt.Tree[int]{}
+	for i := 0; i < 20; i += 2 {
+		tree.Insert(i)
+	}
+
+	sub := tree.SubSet(5, 13)
+	if got := viewValues(sub); !reflect.DeepEqual(got, []int{6, 8, 10, 12}) {
+		t.Fatal("unexpected values", got)
+	}
+
+	if got := viewValues(sub.Descending()); !reflect.DeepEqual(got, []int{12, 10, 8, 6}) {
+		t.Fatal("unexpected descending values", got)
+	}
+
+	if min, _ := sub.Min(); min != 6 {
+		t.Fatal("unexpected min", min)
+	}
+
+	if max, _ := sub.Max(); max != 12 {
+		t.Fatal("unexpected max", max)
+	}
+
+	if sub.Len() != 4 || !sub.Contains(8) || sub.Contains(4) || sub.Contains(7) {
+		t.Fatal("unexpected len or contains", sub.Len())
+	}
+
+	// view is backed by the tree
+	if err := sub.Insert(7); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sub.Insert(13); err != rbt.ErrOutOfRange {
+		t.Fatal("out of range value is inserted", err)
+	}
+
+	if sub.Delete(14) || !sub.Delete(6) {
+		t.Fatal("unexpected delete")
+	}
+
+	tree.Insert(9)
+
+	if got := viewValues(sub); !reflect.DeepEqual(got, []int{7, 8, 9, 10, 12}) {
+		t.Fatal("unexpected values", got)
+	}
+
+	if !tree.Contains(7) || tree.Contains(6) || tree.Len() != 11 {
+		t.Fatal("tree is not changed through view")
+	}
+
+	// nested views are narrowed to parent range
+	if got := viewValues(sub.HeadSet(100).TailSet(9)); !reflect.DeepEqual(got, []int{9, 10, 12}) {
+		t.Fatal("unexpected values", got)
+	}
+
+	if got := viewValues(tree.HeadSet(4)); !reflect.DeepEqual(got, []int{0, 2}) {
+		t.Fatal("unexpected head", got)
+	}
+
+	if got := viewValues(tree.TailSet(15).Descending()); !reflect.DeepEqual(got, []int{18, 16}) {
+		t.Fatal("unexpected tail", got)
+	}
+
+	empty := tree.SubSet(13, 5)
+	if empty.Len() != 0 || len(viewValues(empty)) != 0 || len(viewValues(empty.Descending())) != 0 {
+		t.Fatal("inverted range is not empty")
+	}
+
+	if _, ok := tree.SubSet(100, 200).Max(); ok {
+		t.Fatal("empty view has max")
+	}
+
+	if got := viewValues(tree.Descending()); len(got) != tree.Len() || got[0] != 18 {
+		t.Fatal("unexpected descending tree", got)
+	}
+}
+
+func TestViewLenSized(t *testing.T) {
+	tree := &rbt.Tree[int]{}
+	for i := 0; i < 100; i++ {
+		tree.Insert(i)
+	}
+
+	tree.EnableSubtreeSize()
+	for i := 0; i < 100; i += 3 {
+		tree.Delete(i)
+	}
+
+	for _, v := range []*rbt.View[int]{tree.SubSet(10, 50), tree.HeadSet(40), tree.TailSet(60), tree.SubSet(50, 10)} {
+		if l := len(viewValues(v)); v.Len() != l {
+			t.Fatal("unexpected len", v.Len(), l)
+		}
+	}
+}
@@ -0,0 +1,390 @@
+package rbt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// DefaultSnapshotEvery is number of logged mutations after which
+	// Durable writes snapshot if SnapshotEvery is zero.
+	DefaultSnapshotEvery = 10000
+
+	walLog      = "log"
+	walSnapshot = "snapshot"
+	walMagic    = "RBTSNAP1"
+
+	opInsert byte = 1
+	opDelete byte = 2
+)
+
+// ErrCorruptSnapshot is returned by OpenDurable if snapshot file is damaged.
+var ErrCorruptSnapshot = errors.New("snapshot is corrupt")
+
+// SnapshotError is returned by Insert and Delete if mutation is logged and
+// applied, but automatic snapshot after it failed. Snapshot is tried again
+// after next SnapshotEvery mutations, log keeps growing until then.
+type SnapshotError struct {
+	Err error
+}
+
+func (e *SnapshotError) Error() string {
+	return "snapshot failed: " + e.Err.Error()
+}
+
+func (e *SnapshotError) Unwrap() error {
+	return e.Err
+}
+
+// Durable makes mutations of ordered set (Tree, TreeCmp or any other
+// OrderedSet) durable. Every Insert and Delete is appended to a log with
+// checksum and synced before it is applied to the set, every SnapshotEvery
+// mutations all values are written to a snapshot and the log is started over.
+// OpenDurable restores the set from the snapshot and the tail of the log,
+// record that was written partially during crash is dropped.
+// Set must be read directly and must be changed only through Durable.
+// Record that failed to be written is truncated from the log and the mutation
+// is not applied. If the log cannot be restored or cannot be synced Durable
+// is failed and all later mutations and snapshots return the same error.
+type Durable[T any] struct {
+	Set OrderedSet[T]
+	// SnapshotEvery is number of mutations between snapshots,
+	// zero means DefaultSnapshotEvery.
+	SnapshotEvery int
+	// NoSync disables syncing of log after every mutation. It makes
+	// mutations faster but last of them can be lost after crash.
+	NoSync bool
+
+	dir     string
+	codec   Codec[T]
+	log     *os.File
+	seq     uint64 // sequence number of last logged mutation
+	records int    // records in log
+	skip    int    // records before next automatic snapshot after failed one
+	size    int64  // size of valid records in log
+	rec     []byte
+	err     error
+}
+
+// OpenDurable opens durable set stored in directory dir, set must be empty
+// and is filled with stored values. Values are encoded with codec.
+func OpenDurable[T any](dir string, set OrderedSet[T], codec Codec[T]) (*Durable[T], error) {
+	d := &Durable[T]{
+		Set:   set,
+		dir:   dir,
+		codec: codec,
+		rec:   make([]byte, 1+8+codec.Size()+4),
+	}
+
+	err := d.loadSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.replay()
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// Insert logs insertion of v and inserts v to the set. Value that codec
+// cannot encode is rejected with error of its Check method, see Codec.
+func (d *Durable[T]) Insert(v T) error {
+	err := checkValue(d.codec, v)
+	if err != nil {
+		return err
+	}
+
+	err = d.append(opInsert, v)
+	if err != nil {
+		return err
+	}
+
+	d.Set.Insert(v)
+
+	return d.maybeSnapshot()
+}
+
+// Delete logs deletion of v and deletes v from the set. It returns true if v was found.
+func (d *Durable[T]) Delete(v T) (bool, error) {
+	if d.err != nil {
+		return false, d.err
+	}
+
+	if !d.Set.Contains(v) {
+		return false, nil
+	}
+
+	err := d.append(opDelete, v)
+	if err != nil {
+		return false, err
+	}
+
+	d.Set.Delete(v)
+
+	return true, d.maybeSnapshot()
+}
+
+// Snapshot writes all values of the set to snapshot and starts log over.
+// Snapshot is written to temporary file that replaces old snapshot, so
+// crash during Snapshot leaves previous snapshot and log valid.
+func (d *Durable[T]) Snapshot() error {
+	if d.err != nil {
+		return d.err
+	}
+
+	tmp := filepath.Join(d.dir, walSnapshot+".tmp")
+
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	h := crc32.NewIEEE()
+	w := bufio.NewWriter(io.MultiWriter(f, h))
+
+	hdr := make([]byte, len(walMagic)+16)
+	copy(hdr, walMagic)
+	binary.LittleEndian.PutUint64(hdr[len(walMagic):], d.seq)
+	binary.LittleEndian.PutUint64(hdr[len(walMagic)+8:], uint64(d.Set.Len()))
+	w.Write(hdr)
+
+	b := make([]byte, d.codec.Size())
+	d.Set.Ascend(func(v T) bool {
+		d.codec.Encode(b, v)
+		_, err = w.Write(b)
+		return err == nil
+	})
+
+	if err == nil {
+		err = w.Flush()
+	}
+
+	if err == nil {
+		err = binary.Write(f, binary.LittleEndian, h.Sum32())
+	}
+
+	if err == nil {
+		err = f.Sync()
+	}
+
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Rename(tmp, filepath.Join(d.dir, walSnapshot))
+	}
+
+	if err == nil {
+		err = syncDir(d.dir)
+	}
+
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	// records of log are in snapshot now, they are skipped by sequence
+	// number if crash happens before truncation
+	err = d.log.Truncate(0)
+	if err != nil {
+		d.err = err
+		return err
+	}
+
+	_, err = d.log.Seek(0, io.SeekStart)
+	if err != nil {
+		d.err = err
+		return err
+	}
+
+	d.records = 0
+	d.skip = 0
+	d.size = 0
+
+	return nil
+}
+
+// Close closes log file.
+func (d *Durable[T]) Close() error {
+	return d.log.Close()
+}
+
+func (d *Durable[T]) maybeSnapshot() error {
+	n := d.SnapshotEvery
+	if n == 0 {
+		n = DefaultSnapshotEvery
+	}
+
+	if d.records < n+d.skip {
+		return nil
+	}
+
+	err := d.Snapshot()
+	if err != nil {
+		d.skip = d.records
+		return &SnapshotError{Err: err}
+	}
+
+	return nil
+}
+
+// append writes record of mutation to log. Record is op, sequence number,
+// encoded value and crc32 of all previous bytes.
+func (d *Durable[T]) append(op byte, v T) error {
+	if d.err != nil {
+		return d.err
+	}
+
+	d.rec[0] = op
+	binary.LittleEndian.PutUint64(d.rec[1:], d.seq+1)
+	d.codec.Encode(d.rec[9:len(d.rec)-4], v)
+	binary.LittleEndian.PutUint32(d.rec[len(d.rec)-4:], crc32.ChecksumIEEE(d.rec[:len(d.rec)-4]))
+
+	_, err := d.log.Write(d.rec)
+	if err != nil {
+		return d.rollback(err)
+	}
+
+	if !d.NoSync {
+		err = d.log.Sync()
+		if err != nil {
+			// it is unknown what is on disk after failed sync
+			d.rollback(err)
+			d.err = err
+			return err
+		}
+	}
+
+	d.seq++
+	d.records++
+	d.size += int64(len(d.rec))
+
+	return nil
+}
+
+// rollback drops record that was written partially, so next records follow
+// the last valid one. Durable is failed if log cannot be restored. It returns
+// err of failed append.
+func (d *Durable[T]) rollback(err error) error {
+	rerr := d.log.Truncate(d.size)
+	if rerr == nil {
+		_, rerr = d.log.Seek(d.size, io.SeekStart)
+	}
+
+	if rerr != nil {
+		d.err = rerr
+	}
+
+	return err
+}
+
+func (d *Durable[T]) loadSnapshot() error {
+	b, err := os.ReadFile(filepath.Join(d.dir, walSnapshot))
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	hl := len(walMagic) + 16
+	if len(b) < hl+4 || string(b[:len(walMagic)]) != walMagic {
+		return ErrCorruptSnapshot
+	}
+
+	body := b[:len(b)-4]
+	if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(b[len(b)-4:]) {
+		return ErrCorruptSnapshot
+	}
+
+	d.seq = binary.LittleEndian.Uint64(b[len(walMagic):])
+	n := binary.LittleEndian.Uint64(b[len(walMagic)+8:])
+
+	s := d.codec.Size()
+	if uint64(len(body)-hl) != n*uint64(s) {
+		return ErrCorruptSnapshot
+	}
+
+	for i := hl; i < len(body); i += s {
+		d.Set.Insert(d.codec.Decode(body[i : i+s]))
+	}
+
+	return nil
+}
+
+// replay applies records of log that are newer than snapshot. Log is
+// truncated after last valid record, so new records follow it.
+func (d *Durable[T]) replay() error {
+	f, err := os.OpenFile(filepath.Join(d.dir, walLog), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+
+	r := bufio.NewReader(f)
+	valid := int64(0)
+	for {
+		_, err = io.ReadFull(r, d.rec)
+		if err != nil {
+			// end of log or partially written record
+			break
+		}
+
+		sum := binary.LittleEndian.Uint32(d.rec[len(d.rec)-4:])
+		if crc32.ChecksumIEEE(d.rec[:len(d.rec)-4]) != sum {
+			break
+		}
+
+		valid += int64(len(d.rec))
+		d.records++
+
+		seq := binary.LittleEndian.Uint64(d.rec[1:])
+		if seq <= d.seq {
+			continue
+		}
+
+		d.seq = seq
+		v := d.codec.Decode(d.rec[9 : len(d.rec)-4])
+		if d.rec[0] == opInsert {
+			d.Set.Insert(v)
+		} else {
+			d.Set.Delete(v)
+		}
+	}
+
+	err = f.Truncate(valid)
+	if err == nil {
+		_, err = f.Seek(valid, io.SeekStart)
+	}
+
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	d.log = f
+	d.size = valid
+
+	return nil
+}
+
+// syncDir commits renaming of files in dir.
+func syncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	return f.Sync()
+}
@@ -0,0 +1,290 @@
+package rbt_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gotest.com/rbt"
+)
+
+func openDurable(t *testing.T, dir string) *rbt.Durable[int64] {
+	t.Helper()
+
+	d, err := rbt.OpenDurable[int64](dir, &rbt.Tree[int64]{}, rbt.Int64Codec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return d
+}
+
+func durableValues(d *rbt.Durable[int64]) []int64 {
+	return treeValues(d.Set.(*rbt.Tree[int64]))
+}
+
+func TestDurableRecover(t *testing.T) {
+	dir := t.TempDir()
+
+	d := openDurable(t, dir)
+	d.SnapshotEvery = 7
+
+	for i := int64(0); i < 20; i++ {
+		if err := d.Insert(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := int64(0); i < 20; i += 3 {
+		if ok, err := d.Delete(i); err != nil || !ok {
+			t.Fatal("value is not deleted", i, err)
+		}
+	}
+
+	if ok, _ := d.Delete(100); ok {
+		t.Fatal("missing value is deleted")
+	}
+
+	expected := durableValues(d)
+
+	err := d.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d = openDurable(t, dir)
+	defer d.Close()
+
+	if got := durableValues(d); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("recovered %v, expected %v", got, expected)
+	}
+}
+
+func TestDurableTruncatedLog(t *testing.T) {
+	dir := t.TempDir()
+
+	d := openDurable(t, dir)
+	for i := int64(0); i < 10; i++ {
+		if err := d.Insert(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d.Close()
+
+	// cut last record in the middle as if crash happened during write
+	log := filepath.Join(dir, "log")
+	st, err := os.Stat(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.Truncate(log, st.Size()-5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d = openDurable(t, dir)
+
+	expected := []int64{0, 1, 2, 3, 4, 5, 6, 7, 8}
+	if got := durableValues(d); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("recovered %v, expected %v", got, expected)
+	}
+
+	// new records must follow last valid one
+	if err := d.Insert(42); err != nil {
+		t.Fatal(err)
+	}
+
+	d.Close()
+
+	d = openDurable(t, dir)
+	defer d.Close()
+
+	expected = append(expected, 42)
+	if got := durableValues(d); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("recovered %v, expected %v", got, expected)
+	}
+}
+
+func TestDurableStaleLog(t *testing.T) {
+	dir := t.TempDir()
+
+	d := openDurable(t, dir)
+	for i := int64(0); i < 5; i++ {
+		if err := d.Insert(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	log := filepath.Join(dir, "log")
+	old, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d.Close()
+
+	// crash after snapshot is written but before log is truncated
+	err = os.WriteFile(log, old, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d = openDurable(t, dir)
+	defer d.Close()
+
+	expected := []int64{0, 1, 2, 3, 4}
+	if got := durableValues(d); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("recovered %v, expected %v", got, expected)
+	}
+}
+
+func TestDurableCorruptSnapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	d := openDurable(t, dir)
+	d.Insert(1)
+	d.Snapshot()
+	d.Close()
+
+	snap := filepath.Join(dir, "snapshot")
+	b, err := os.ReadFile(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b[len(b)-5] ^= 0xff
+	os.WriteFile(snap, b, 0o644)
+
+	_, err = rbt.OpenDurable[int64](dir, &rbt.Tree[int64]{}, rbt.Int64Codec{})
+	if err != rbt.ErrCorruptSnapshot {
+		t.Fatal("corrupt snapshot is not detected", err)
+	}
+}
+
+func TestDurableValueTooLong(t *testing.T) {
+	dir := t.TempDir()
+
+	d, err := rbt.OpenDurable[string](dir, &rbt.Tree[string]{}, rbt.StringCodec{Len: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = d.Insert("long value"); err != rbt.ErrValueTooLong {
+		t.Fatal("long value is inserted", err)
+	}
+
+	if d.Set.Contains("long value") {
+		t.Fatal("rejected value is in set")
+	}
+
+	d.Close()
+}
+
+func TestDurableFailedAppend(t *testing.T) {
+	dir := t.TempDir()
+
+	d := openDurable(t, dir)
+	for i := int64(0); i < 5; i++ {
+		if err := d.Insert(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// log cannot be written nor restored after it is closed
+	d.Close()
+
+	err := d.Insert(5)
+	if err == nil {
+		t.Fatal("insert to closed log succeeded")
+	}
+
+	// log cannot be restored, so durable is failed and rejects even
+	// deletion of missing value and snapshot
+	if ok, err2 := d.Delete(1); ok || err2 == nil {
+		t.Fatal("failed durable is changed", ok, err2)
+	}
+
+	if ok, err2 := d.Delete(42); ok || err2 == nil {
+		t.Fatal("delete from failed durable succeeded", ok)
+	}
+
+	if err2 := d.Snapshot(); err2 == nil {
+		t.Fatal("snapshot of failed durable succeeded")
+	}
+
+	expected := []int64{0, 1, 2, 3, 4}
+	if got := durableValues(d); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("set is %v after failed append, expected %v", got, expected)
+	}
+
+	d = openDurable(t, dir)
+	defer d.Close()
+
+	if got := durableValues(d); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("recovered %v, expected %v", got, expected)
+	}
+
+	if err := d.Insert(5); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDurableFailedSnapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	// directory in place of temporary snapshot file makes snapshots fail
+	tmp := filepath.Join(dir, "snapshot.tmp")
+	err := os.Mkdir(tmp, 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := openDurable(t, dir)
+	d.SnapshotEvery = 3
+
+	var serr *rbt.SnapshotError
+	for i := int64(0); i < 9; i++ {
+		err = d.Insert(i)
+
+		// snapshot is tried after every 3 mutations, not after every one
+		if i%3 == 2 {
+			if !errors.As(err, &serr) {
+				t.Fatal("snapshot error is not returned", i, err)
+			}
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if ok, err := d.Delete(0); !ok || err != nil {
+		t.Fatal("value is not deleted after failed snapshot", ok, err)
+	}
+
+	err = os.Remove(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = d.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+
+	d.Close()
+
+	d = openDurable(t, dir)
+	defer d.Close()
+
+	expected := []int64{1, 2, 3, 4, 5, 6, 7, 8}
+	if got := durableValues(d); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("recovered %v, expected %v", got, expected)
+	}
+}